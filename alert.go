@@ -0,0 +1,554 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type alertKind int
+
+const (
+	alertBreach alertKind = iota
+	alertRecovery
+	alertBudget
+	// alerts of a run grouped into one message
+	alertDigest
+)
+
+const (
+	severityCritical = "critical"
+	severityWarning  = "warning"
+)
+
+type Alert struct {
+	Kind alertKind
+	// name of the check raising the alert, empty for the wallet balance,
+	// and what it checks if a wallet has several
+	Check    string
+	Target   string
+	Severity string
+	// first alert since the wallet went below threshold
+	New     bool
+	Network string
+	Wallet  string
+	Address string
+	// balance and threshold as text in the configured number format
+	Balance string
+	// unformatted balance and threshold in display units and the balance in
+	// base units for machine readable sinks, nil if unknown
+	Amount, Limit *big.Float
+	RawAmount     *big.Int
+	// the wallet holds nothing at all
+	Empty bool
+	// how far the balance is below threshold, see breachTier
+	Tier      int
+	Threshold string
+	Coin      string
+	Explorer  string
+	Contact   Contact
+	LastTx    *Transaction
+	Staked    string
+	Rewards   string
+	Detail    string
+	// balance change since the previous check
+	Delta string
+	// month to date and projected spend of budget alerts
+	Spent     string
+	Projected string
+	Budget    string
+	// named channels of the wallet, the default ones if empty
+	Channels []string
+	// grouped alerts of a digest
+	Alerts []Alert
+	// alerts a rate limited sink dropped, a digest without alerts reports them
+	Suppressed int
+	// step the breach escalated to, nil if it did not
+	Escalation *EscalationStep
+}
+
+func (a Alert) explorerURL() string {
+	return explorerURL(a.Explorer, a.Address)
+}
+
+// markdown link to the address on the explorer, the bare address without one
+func (a Alert) addressLink() string {
+	if u := a.explorerURL(); u != "" {
+		return fmt.Sprintf("[%s](%s)", a.Address, u)
+	}
+	return a.Address
+}
+
+func (a Alert) key() string {
+	if a.Target != "" {
+		return walletKey(a.Network, a.Address) + "/" + a.Check + "/" + a.Target
+	}
+	if a.Check != "" {
+		return walletKey(a.Network, a.Address) + "/" + a.Check
+	}
+	return walletKey(a.Network, a.Address)
+}
+
+// what the alert is about
+func (a Alert) subject() string {
+	if a.Target != "" {
+		return a.Coin + " " + strings.ReplaceAll(a.Check, "_", " ")
+	}
+	if a.Check != "" {
+		return strings.ReplaceAll(a.Check, "_", " ")
+	}
+	return "balance"
+}
+
+func (a Alert) emoji() string {
+	switch {
+	case a.Kind == alertRecovery:
+		return "✅"
+	case a.Empty:
+		return "🆘"
+	case a.Check == checkEndpoint:
+		return "🔌"
+	case a.Severity == severityWarning:
+		return "⚠️"
+	}
+	return "🚨"
+}
+
+// translated title of a breach or recovery alert
+func (a Alert) heading(lang string) string {
+	switch {
+	case a.Kind == alertRecovery && a.Check == "":
+		return tr(lang, "restored")
+	case a.Kind == alertRecovery:
+		return tr(lang, a.Check+"_alert") + " – " + tr(lang, "resolved")
+	case a.Check != "":
+		return tr(lang, a.Check+"_alert")
+	case a.Empty:
+		return tr(lang, "empty_alert")
+	case a.Severity == severityWarning:
+		return tr(lang, "warning_alert")
+	}
+	return tr(lang, "alert")
+}
+
+// one line summary for channels with a title
+func (a Alert) title() string {
+	if a.Kind == alertDigest {
+		return plainText(a.digestHeader(""))
+	}
+	if a.Check == checkEndpoint && a.Kind == alertRecovery {
+		return fmt.Sprintf("%s rpc endpoint %s recovered", a.Network, a.Target)
+	}
+	if a.Check == checkEndpoint {
+		return fmt.Sprintf("%s rpc endpoint %s failing", a.Network, a.Target)
+	}
+	if a.Kind == alertRecovery {
+		return fmt.Sprintf("%s %s %s recovered", a.Network, a.Wallet, a.subject())
+	}
+	if a.Empty {
+		return fmt.Sprintf("%s %s is empty", a.Network, a.Wallet)
+	}
+	return fmt.Sprintf("%s %s %s below threshold", a.Network, a.Wallet, a.subject())
+}
+
+// markdown message shared by the chat channels
+func (a Alert) message(lang string) string {
+	if a.Kind == alertDigest {
+		return a.digestMessage(lang, Alert.line)
+	}
+	if a.Kind == alertBudget {
+		scope := a.Wallet
+		if scope == "" {
+			scope = tr(lang, "all_wallets")
+		}
+		return fmt.Sprintf("💸 **%s** %s 💸\n\n%s: %s\n%s: %s %s\n%s: %s %s\n%s: %s %s\n\n", a.Network, tr(lang, "budget_alert"),
+			tr(lang, "scope"), scope,
+			tr(lang, "spent_month"), a.Spent, a.Coin,
+			tr(lang, "projected"), a.Projected, a.Coin,
+			tr(lang, "budget"), a.Budget, a.Coin)
+	}
+	if a.Check == checkEndpoint {
+		msg := fmt.Sprintf("%s **%s** %s %s\n\n%s: %s\n", a.emoji(), a.Network, a.heading(lang), a.emoji(), tr(lang, "endpoint"), a.Target)
+		if a.Detail != "" {
+			msg += a.Detail + "\n"
+		}
+		return msg + "\n"
+	}
+	balance := tr(lang, "balance")
+	if a.Check != "" && a.Check != "token" {
+		balance = tr(lang, "remaining")
+	}
+	msg := fmt.Sprintf("%s **%s** %s %s\n\n%s: %s\n%s: %s\n%s: %s %s\n%s: %s %s\n", a.emoji(), a.Network, a.heading(lang), a.emoji(),
+		tr(lang, "wallet"), a.Wallet,
+		tr(lang, "address"), a.addressLink(),
+		balance, a.Balance, a.Coin,
+		tr(lang, "threshold"), a.Threshold, a.Coin)
+	if a.Staked != "" {
+		msg += fmt.Sprintf("%s: %s %s\n", tr(lang, "staked"), a.Staked, a.Coin)
+	}
+	if a.Rewards != "" && a.Rewards != "0" {
+		msg += fmt.Sprintf("%s: %s %s, %s\n", tr(lang, "rewards"), a.Rewards, a.Coin, tr(lang, "claim_first"))
+	}
+	if a.Detail != "" {
+		msg += a.Detail + "\n"
+	}
+	if e := a.Escalation; e != nil {
+		msg += fmt.Sprintf("%s: %d %s\n", tr(lang, "escalated"), e.After, tr(lang, "checks"))
+	}
+	if tx := a.LastTx; tx != nil {
+		msg += fmt.Sprintf("%s: %s\n", tr(lang, "last_tx"), tx.summary(a.Coin))
+	}
+	return msg + "\n"
+}
+
+type CardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// heading and labelled details of an alert for card based channels
+func (a Alert) facts(lang string) (heading string, facts []CardFact) {
+	if a.Kind == alertDigest {
+		for _, alert := range a.Alerts {
+			facts = append(facts, CardFact{alert.emoji() + " " + alert.Network + " " + alert.Wallet, alert.Balance + " / " + alert.Threshold + " " + alert.Coin})
+		}
+		return plainText(a.digestHeader(lang)), facts
+	}
+	if a.Kind == alertBudget {
+		scope := a.Wallet
+		if scope == "" {
+			scope = tr(lang, "all_wallets")
+		}
+		heading = fmt.Sprintf("%s %s", a.Network, tr(lang, "budget_alert"))
+		facts = []CardFact{
+			{tr(lang, "scope"), scope},
+			{tr(lang, "spent_month"), a.Spent + " " + a.Coin},
+			{tr(lang, "projected"), a.Projected + " " + a.Coin},
+			{tr(lang, "budget"), a.Budget + " " + a.Coin},
+		}
+	} else if a.Check == checkEndpoint {
+		heading = fmt.Sprintf("%s %s", a.Network, a.heading(lang))
+		facts = []CardFact{{tr(lang, "endpoint"), a.Target}}
+		if a.Detail != "" {
+			facts = append(facts, CardFact{"", a.Detail})
+		}
+	} else {
+		heading = fmt.Sprintf("%s %s", a.Network, a.heading(lang))
+		facts = []CardFact{
+			{tr(lang, "wallet"), a.Wallet},
+			{tr(lang, "address"), a.Address},
+			{tr(lang, "balance"), a.Balance + " " + a.Coin},
+			{tr(lang, "threshold"), a.Threshold + " " + a.Coin},
+		}
+		if a.Staked != "" {
+			facts = append(facts, CardFact{tr(lang, "staked"), a.Staked + " " + a.Coin})
+		}
+		if a.Detail != "" {
+			facts = append(facts, CardFact{"", a.Detail})
+		}
+		if e := a.Escalation; e != nil {
+			facts = append(facts, CardFact{tr(lang, "escalated"), fmt.Sprintf("%d %s", e.After, tr(lang, "checks"))})
+		}
+		if tx := a.LastTx; tx != nil {
+			facts = append(facts, CardFact{tr(lang, "last_tx"), tx.summary(a.Coin)})
+		}
+	}
+	return heading, facts
+}
+
+// contact to mention on an alert, the owner of the wallet or else of the
+// network together with the contact of the severity
+func (c *ChainConfig) contact(networkConfig NetworkConfig, owner, severity string) Contact {
+	contact := c.Contacts[cmp.Or(owner, networkConfig.Owner)]
+	if name, ok := c.SeverityContacts[severity]; ok {
+		contact = contact.merge(c.Contacts[name])
+	}
+	return contact
+}
+
+func (c Contact) discordMentions() string {
+	var mentions []string
+	for _, id := range c.DiscordRoles {
+		mentions = append(mentions, "<@&"+id+">")
+	}
+	for _, id := range c.DiscordUsers {
+		mentions = append(mentions, "<@"+id+">")
+	}
+	return strings.Join(mentions, " ")
+}
+
+func (c Contact) telegramMentions() string {
+	var mentions []string
+	for _, username := range c.Telegram {
+		mentions = append(mentions, "@"+strings.TrimPrefix(username, "@"))
+	}
+	return strings.Join(mentions, " ")
+}
+
+// slack user ids start with U or W, user groups with S
+func (c Contact) slackMentions() string {
+	var mentions []string
+	for _, id := range c.Slack {
+		if strings.HasPrefix(id, "S") {
+			mentions = append(mentions, "<!subteam^"+id+">")
+		} else {
+			mentions = append(mentions, "<@"+id+">")
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+// prefix the message with mentions if there are any
+func withMentions(mentions, message string) string {
+	if mentions == "" {
+		return message
+	}
+	return mentions + "\n" + message
+}
+
+type notifier interface {
+	notify(alert Alert) error
+}
+
+type notifierFunc func(alert Alert) error
+
+// paging channels are not woken up for warnings, which stay in chat, and
+// only get the critical breaches of a digest
+func criticalOnly(fn func(alert Alert) error) func(alert Alert) error {
+	return func(alert Alert) error {
+		switch alert.Kind {
+		case alertBreach:
+			if alert.Severity != severityCritical {
+				return nil
+			}
+		case alertDigest:
+			var critical []Alert
+			for _, a := range alert.Alerts {
+				if a.Kind == alertBreach && a.Severity == severityCritical {
+					critical = append(critical, a)
+				}
+			}
+			switch len(critical) {
+			case 0:
+				return nil
+			case 1:
+				alert = critical[0]
+			default:
+				alert = digestAlert(critical)
+			}
+		}
+		return fn(alert)
+	}
+}
+
+func (f notifierFunc) notify(alert Alert) error {
+	return f(alert)
+}
+
+// channels configured through the environment and the named channels
+func notifiers(chainCfg *ChainConfig) []notifier {
+	ns := channelNotifiers(chainCfg)
+	if discordWebhookURL != "" {
+		lang := chainCfg.language("discord")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("discord", retried(func(alert Alert) error {
+			return sendDiscordAlert(discordWebhookURL, chainCfg.discordMessage("discord", alert, lang))
+		})))))
+	}
+	if telegramBotToken != "" && telegramChatID != "" {
+		lang := chainCfg.language("telegram")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("telegram", retried(func(alert Alert) error {
+			return sendTelegramAlert(telegramChatID, withMentions(alert.Contact.telegramMentions(), chainCfg.alertText("telegram", alert, lang)))
+		})))))
+	}
+	if slackBotToken != "" && slackChannel != "" {
+		lang := chainCfg.language("slack")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("slack", retried(func(alert Alert) error {
+			return sendSlackAlert(alert, chainCfg.alertText("slack", alert, lang), lang)
+		})))))
+	}
+	if slackWebhookURL != "" {
+		lang := chainCfg.language("slack")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("slack_webhook", retried(func(alert Alert) error {
+			return sendSlackWebhook(slackWebhookURL, withMentions(alert.Contact.slackMentions(), slackMarkdown(chainCfg.alertText("slack", alert, lang))))
+		})))))
+	}
+	if grafanaURL != "" && grafanaAPIKey != "" {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("grafana", retried(sendGrafanaAnnotation)))))
+	}
+	if ntfyTopic != "" {
+		lang := chainCfg.language("ntfy")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("ntfy", retried(func(alert Alert) error {
+			return sendNtfyAlert(alert, chainCfg.alertText("ntfy", alert, lang))
+		})))))
+	}
+	if pushoverToken != "" && pushoverUser != "" {
+		lang := chainCfg.language("pushover")
+		ns = append(ns, notifierFunc(criticalOnly(defaultRoute(chainCfg.limitChat("pushover", retried(func(alert Alert) error {
+			return sendPushoverAlert(alert, chainCfg.alertText("pushover", alert, lang))
+		}))))))
+	}
+	if googleChatWebhookURL != "" {
+		lang := chainCfg.language("google_chat")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("google_chat", retried(func(alert Alert) error {
+			return sendGoogleChatAlert(googleChatWebhookURL, alert, lang)
+		})))))
+	}
+	if teamsWebhookURL != "" {
+		lang := chainCfg.language("teams")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("teams", retried(func(alert Alert) error {
+			return sendTeamsAlert(teamsWebhookURL, alert, lang)
+		})))))
+	}
+	if c := chainCfg.Matrix; c != nil {
+		lang := chainCfg.language("matrix")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("matrix", func(alert Alert) error {
+			return sendMatrixAlert(c, chainCfg.alertText("matrix", alert, lang))
+		}))))
+	}
+	if emailConfigured() {
+		lang := chainCfg.language("email")
+		ns = append(ns, notifierFunc(defaultRoute(chainCfg.limitChat("email", retried(func(alert Alert) error {
+			return sendEmailAlert(alert, chainCfg.alertText("email", alert, lang), lang)
+		})))))
+	}
+	if twilioAccountSID != "" && twilioAuthToken != "" && twilioFrom != "" && twilioTo != "" {
+		ns = append(ns, notifierFunc(perAlert(criticalOnly(defaultRoute(chainCfg.limit("twilio", sendTwilioSMS))))))
+	}
+	if len(chainCfg.Webhooks) > 0 {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("webhooks", func(alert Alert) error {
+			return sendWebhooks(chainCfg.Webhooks, alert)
+		}))))
+	}
+	if alertmanagerURL != "" {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("alertmanager", retried(sendAlertmanagerAlert)))))
+	}
+	if snsTopicARN != "" {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("sns", retried(func(alert Alert) error {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			return publishSNS(ctx, alert)
+		})))))
+	}
+	if opsgenieAPIKey != "" {
+		ns = append(ns, notifierFunc(perAlert(defaultRoute(chainCfg.limit("opsgenie", retried(sendOpsgenieAlert))))))
+	}
+	if datadogAPIKey != "" {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("datadog", retried(sendDatadogEvent)))))
+	}
+	if *githubBreachIssues && githubToken != "" {
+		ns = append(ns, notifierFunc(perAlert(chainCfg.limit("github", retried(func(alert Alert) error {
+			return sendGitHubIssue(chainCfg, alert)
+		})))))
+	}
+	return ns
+}
+
+// how long a breach must persist before it alerts, and how long to wait
+// before alerting again
+type breachRule struct {
+	count      int
+	duration   time.Duration
+	cooldown   time.Duration
+	escalation []EscalationStep
+}
+
+func (r breachRule) pending(ws *WalletState, now time.Time) bool {
+	return ws.ConsecutiveBreaches < r.count || now.Sub(ws.BreachedSince) < r.duration
+}
+
+// repeated alerts wait for the cooldown unless the balance fell a tier lower
+func (r breachRule) coolingDown(ws *WalletState, tier int, now time.Time) bool {
+	return now.Sub(ws.LastAlerted) < r.cooldown && tier <= ws.AlertedTier
+}
+
+// how far a balance is below threshold, each tier halves it
+func breachTier(balance, threshold *big.Float) int {
+	tier := 0
+	limit := new(big.Float).Quo(threshold, big.NewFloat(2))
+	for balance.Cmp(limit) < 0 && tier < maxBreachTier {
+		tier++
+		limit.Quo(limit, big.NewFloat(2))
+	}
+	return tier
+}
+
+// tier of empty wallets
+const maxBreachTier = 16
+
+// record the breach state and decide whether the alert is sent,
+// it is not while pending, acknowledged or snoozed
+func evaluateAlert(store *stateStore, alert Alert, breached bool, rule breachRule) (Alert, bool, error) {
+	var notify bool
+	err := store.update(func(st *AlertState) error {
+		ws := st.wallet(alert.key())
+		if !breached {
+			if ws.Breached {
+				alert.Kind = alertRecovery
+				// a recovery resolves the breach at the severity it alerted
+				alert.Severity = cmp.Or(ws.Severity, alert.Severity)
+				if ws.Escalation > 0 && ws.Escalation <= len(rule.escalation) {
+					alert.Severity = rule.escalation[ws.Escalation-1].severity(alert.Severity)
+				}
+				// recoveries do not page anyone
+				alert.Contact = Contact{}
+				notify = true
+			}
+			ws.Breached = false
+			ws.Acknowledged = false
+			ws.AcknowledgedBy = ""
+			ws.ConsecutiveBreaches = 0
+			ws.BreachedSince = time.Time{}
+			ws.LastAlerted = time.Time{}
+			ws.AlertedTier = 0
+			ws.Severity = ""
+			ws.Empty = false
+			ws.Escalation = 0
+			return nil
+		}
+
+		now := time.Now()
+		ws.ConsecutiveBreaches++
+		if ws.BreachedSince.IsZero() {
+			ws.BreachedSince = now
+		}
+		// a wallet running dry alerts right away, whatever the rule
+		emptied := alert.Empty && !ws.Empty
+		ws.Empty = alert.Empty
+		if !ws.Breached && !emptied && rule.pending(ws, now) {
+			slog.Info("Breach pending", "chain", alert.Network, "wallet", cmp.Or(alert.Wallet, alert.Target), "consecutive", ws.ConsecutiveBreaches, "since", ws.BreachedSince.UTC())
+			return nil
+		}
+		// a breach turning critical or back into a warning alerts like a new one
+		changed := ws.Breached && (ws.Severity != "" && ws.Severity != alert.Severity || emptied)
+		alert.New = !ws.Breached || changed
+		// a breach lasting long enough escalates to the next step
+		escalated := false
+		for ws.Breached && ws.Escalation < len(rule.escalation) && ws.ConsecutiveBreaches >= rule.escalation[ws.Escalation].After {
+			ws.Escalation++
+			escalated = true
+		}
+		if ws.Breached && !changed && !escalated && rule.coolingDown(ws, alert.Tier, now) {
+			return nil
+		}
+		ws.Breached = true
+		ws.Severity = alert.Severity
+		if ws.Escalation > 0 {
+			alert = rule.escalation[ws.Escalation-1].apply(alert)
+		}
+		notify = !ws.silenced(now)
+		if notify {
+			ws.LastAlerted = now
+			ws.AlertedTier = alert.Tier
+		}
+		return nil
+	})
+	if err != nil {
+		// alert anyway, losing state must not swallow a breach
+		return alert, breached, err
+	}
+	return alert, notify, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	alertmanagerURL = os.Getenv("ALERTMANAGER_URL")
+	// firing alerts resolve on their own unless re-sent within this period,
+	// daemon mode stretches it to three runs of its longest schedule
+	alertmanagerEndsAfter = time.Hour
+)
+
+type AlertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+var alertmanagerNames = map[alertKind]string{
+	alertBreach:   "WalletBalanceLow",
+	alertRecovery: "WalletBalanceLow",
+	alertBudget:   "WalletBudgetExceeded",
+}
+
+// post the alert so routing, silences and inhibition are left to
+// alertmanager, recoveries resolve the alert
+func sendAlertmanagerAlert(alert Alert) error {
+	labels := map[string]string{
+		"alertname": alertmanagerNames[alert.Kind],
+		"chain":     alert.Network,
+		"wallet":    alert.Wallet,
+		"severity":  alert.Severity,
+	}
+	if alert.Address != "" {
+		labels["address"] = alert.Address
+	}
+	if alert.Check != "" {
+		labels["check"] = alert.Check
+	}
+	if alert.Target != "" {
+		labels["target"] = alert.Target
+	}
+	now := time.Now().UTC()
+	a := AlertmanagerAlert{
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("%s %s %s below threshold", alert.Network, alert.Wallet, alert.subject()),
+			"description": plainText(alert.message("")),
+		},
+		EndsAt: now.Add(alertmanagerEndsAfter),
+	}
+	switch alert.Kind {
+	case alertBudget:
+		a.Annotations["summary"] = fmt.Sprintf("%s projected spend of %s %s exceeds the budget of %s", alert.Network, alert.Projected, alert.Coin, alert.Budget)
+	case alertRecovery:
+		a.EndsAt = now
+		a.Annotations["summary"] = fmt.Sprintf("%s %s %s recovered", alert.Network, alert.Wallet, alert.subject())
+	}
+	a.GeneratorURL = alert.explorerURL()
+
+	jsonMsg, err := json.Marshal([]AlertmanagerAlert{a})
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(strings.TrimSuffix(alertmanagerURL, "/")+"/api/v2/alerts", "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
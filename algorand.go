@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+)
+
+type AlgorandAccount struct {
+	Amount     int64 `json:"amount"`
+	MinBalance int64 `json:"min-balance"`
+}
+
+// spendable microalgos, the balance less the minimum balance the account
+// has to keep for its assets and apps; indexer responses wrap the account
+func getAlgorandBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	var res struct {
+		AlgorandAccount
+		Account *AlgorandAccount `json:"account"`
+	}
+	if err := getJSON(ctx, fmt.Sprintf("%s/v2/accounts/%s?exclude=all", rpcURL, url.PathEscape(address)), &res); err != nil {
+		return nil, err
+	}
+	account := res.AlgorandAccount
+	if res.Account != nil {
+		account = *res.Account
+	}
+	return big.NewInt(max(account.Amount-account.MinBalance, 0)), nil
+}
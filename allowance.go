@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"strings"
+)
+
+// alert when an erc-20 approval of the wallet to a spender runs low
+type AllowanceCheck struct {
+	Token     string `json:"token"`
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+	Spender   string `json:"spender"`
+	Threshold string `json:"threshold"`
+}
+
+// allowance(address,address)
+const allowanceSelector = "0xdd62ed3e"
+
+func abiAddress(address string) string {
+	return fmt.Sprintf("%064s", strings.ToLower(strings.TrimPrefix(address, "0x")))
+}
+
+func getERC20Allowance(ctx context.Context, rpcURL, token, owner, spender string) (*big.Int, error) {
+	call := map[string]string{
+		"to":   token,
+		"data": allowanceSelector + abiAddress(owner) + abiAddress(spender),
+	}
+	var result string
+	if err := callJSONRPC(ctx, rpcURL, "eth_call", []any{call, "latest"}, &result); err != nil {
+		return nil, err
+	}
+	allowance, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid allowance %q", result)
+	}
+	return allowance, nil
+}
+
+// check the token approvals of an evm network's wallets
+func (m *monitor) checkAllowances(ctx context.Context, out io.Writer, networkConfig NetworkConfig) {
+	if networkConfig.Type != "evm" {
+		return
+	}
+	for _, w := range networkConfig.Wallets {
+		for _, check := range w.Allowances {
+			if err := m.checkAllowance(ctx, out, networkConfig, w, check); err != nil {
+				slog.Error("Checking allowance failed", "chain", networkConfig.Name, "wallet", w.Name, "token", check.Symbol, "err", err)
+			}
+		}
+	}
+}
+
+func (m *monitor) checkAllowance(ctx context.Context, out io.Writer, networkConfig NetworkConfig, w Wallet, check AllowanceCheck) error {
+	threshold, ok := new(big.Float).SetString(check.Threshold)
+	if !ok {
+		return fmt.Errorf("error parsing allowance threshold value")
+	}
+	raw, err := getERC20Allowance(ctx, networkConfig.RPC, check.Token, w.Address, check.Spender)
+	if err != nil {
+		return err
+	}
+	allowance := toDecimalUnit(raw, check.Decimals)
+	fmt.Fprintf(out, prettyFormat, w.Address, "allowance "+m.chainCfg.Format.format(allowance), check.Symbol+" to "+check.Spender, m.chainCfg.Format.format(threshold))
+
+	alert := Alert{
+		Check:     "allowance",
+		Target:    check.Token + "/" + check.Spender,
+		Severity:  severityCritical,
+		Network:   networkConfig.Name,
+		Wallet:    w.Name,
+		Address:   w.Address,
+		Balance:   m.chainCfg.Format.format(allowance),
+		Threshold: m.chainCfg.Format.format(threshold),
+		Amount:    allowance,
+		Limit:     threshold,
+		RawAmount: raw,
+		Coin:      check.Symbol,
+		Explorer:  networkConfig.Explorer,
+		Contact:   m.chainCfg.contact(networkConfig, w.Owner, severityCritical),
+		Channels:  w.Channels,
+		Detail:    "spender " + check.Spender,
+	}
+	rule, err := networkConfig.breachRule(w)
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "wallet", w.Name, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, exceedsBalanceThreshold(allowance, threshold), rule)
+	if notify {
+		m.notify(alert)
+	}
+	return err
+}
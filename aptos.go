@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+const aptosCoinType = "0x1::aptos_coin::AptosCoin"
+
+type AptosViewRequest struct {
+	Function      string   `json:"function"`
+	TypeArguments []string `json:"type_arguments"`
+	Arguments     []string `json:"arguments"`
+}
+
+// balance in octas, the view function covers both the coin store
+// and the fungible asset store of migrated accounts
+func getAptosBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	var res []string
+	req := AptosViewRequest{
+		Function:      "0x1::coin::balance",
+		TypeArguments: []string{aptosCoinType},
+		Arguments:     []string{address},
+	}
+	if err := postJSON(ctx, rpcURL+"/view", req, &res); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty aptos balance response")
+	}
+	balance, ok := new(big.Int).SetString(res[0], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid aptos balance %q", res[0])
+	}
+	return balance, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32Encode(hrp string, data []byte) string {
+	// regroup 8 bit bytes into 5 bit words
+	var words []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			words = append(words, byte(acc>>bits&31))
+		}
+	}
+	if bits > 0 {
+		words = append(words, byte(acc<<(5-bits)&31))
+	}
+
+	var values []byte
+	for _, c := range hrp {
+		values = append(values, byte(c>>5))
+	}
+	values = append(values, 0)
+	for _, c := range hrp {
+		values = append(values, byte(c&31))
+	}
+	values = append(values, words...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var sb strings.Builder
+	sb.WriteString(hrp + "1")
+	for _, w := range words {
+		sb.WriteByte(bech32Charset[w])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[polymod>>(5*(5-i))&31])
+	}
+	return sb.String()
+}
+
+// ethermint chains like injective derive account addresses from evm keys,
+// so an evm address is the same account in bech32 form
+func evmToBech32(prefix, address string) (string, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(data) != 20 {
+		return "", fmt.Errorf("invalid evm address %s", address)
+	}
+	return bech32Encode(prefix, data), nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	// consecutive failed balance queries before an endpoint's breaker opens
+	defaultBreakerFailures = 5
+	// how long an open breaker skips the endpoint
+	defaultBreakerCooldown = 5 * time.Minute
+)
+
+var errBreakerOpen = errors.New("circuit breaker open")
+
+// circuit breaker of an rpc endpoint, after the cool-down the next query
+// is let through and a failure opens the breaker again
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+}
+
+// breakers live as long as the process, like the rate limits
+var breakers = struct {
+	sync.Mutex
+	endpoints map[string]*circuitBreaker
+}{endpoints: make(map[string]*circuitBreaker)}
+
+func (n NetworkConfig) breakerFailures() int {
+	return cmp.Or(n.BreakerFailures, defaultBreakerFailures)
+}
+
+func (n NetworkConfig) breakerCooldown() time.Duration {
+	if d, err := time.ParseDuration(n.BreakerCooldown); err == nil {
+		return d
+	}
+	return defaultBreakerCooldown
+}
+
+// error of an endpoint whose breaker is open, nil while it is closed
+func breakerError(endpoint string, now time.Time) error {
+	breakers.Lock()
+	defer breakers.Unlock()
+	b, ok := breakers.endpoints[endpoint]
+	if !ok || !now.Before(b.openUntil) {
+		return nil
+	}
+	return fmt.Errorf("%w until %s", errBreakerOpen, b.openUntil.UTC().Format(time.RFC3339))
+}
+
+// count a failed query against the endpoint, a success closes its breaker,
+// errors of the query itself such as an invalid address do not count
+func (n NetworkConfig) recordBreaker(endpoint string, err error) {
+	if err != nil && !transient(err) {
+		return
+	}
+	breakers.Lock()
+	defer breakers.Unlock()
+	b, ok := breakers.endpoints[endpoint]
+	if !ok {
+		b = &circuitBreaker{}
+		breakers.endpoints[endpoint] = b
+	}
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	now := time.Now()
+	if b.failures < n.breakerFailures() || now.Before(b.openUntil) {
+		return
+	}
+	b.openUntil = now.Add(n.breakerCooldown())
+	slog.Warn("Circuit breaker opened", "chain", n.Name, "endpoint", endpoint, "failures", b.failures, "cooldown", n.breakerCooldown())
+}
+
+// guard the balance queries of an endpoint with its breaker, a query and its
+// retries count once
+func (n NetworkConfig) withBreaker(endpoint string, fetch func(ctx context.Context, address string) (*big.Int, error)) func(ctx context.Context, address string) (*big.Int, error) {
+	return func(ctx context.Context, address string) (*big.Int, error) {
+		if err := breakerError(endpoint, time.Now()); err != nil {
+			return nil, err
+		}
+		balance, err := fetch(ctx, address)
+		// queries cut short by the shutdown or the chain timeout say
+		// nothing about the endpoint
+		if ctx.Err() == nil {
+			n.recordBreaker(endpoint, err)
+		}
+		return balance, err
+	}
+}
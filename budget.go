@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+type BudgetStatus struct {
+	Network string
+	// empty for the budget of the whole network
+	Wallet    string
+	Address   string
+	Coin      string
+	Spent     float64
+	Projected float64
+	Budget    float64
+}
+
+func (b BudgetStatus) over() bool {
+	return b.Projected > b.Budget
+}
+
+func (b BudgetStatus) key() string {
+	if b.Wallet == "" {
+		return walletKey(b.Network, "budget")
+	}
+	return walletKey(b.Network, b.Address) + "/budget"
+}
+
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// month to date spend and projected month end spend of every budget
+func buildBudgets(chainCfg *ChainConfig, samples []Sample, now time.Time) ([]BudgetStatus, error) {
+	from := monthStart(now)
+	remaining := from.AddDate(0, 1, 0).Sub(now).Hours() / 24
+	r := buildReport(samples, from, now)
+
+	var budgets []BudgetStatus
+	for _, networkConfig := range chainCfg.Chains {
+		var chain *ChainReport
+		for _, cr := range r.Chains {
+			if cr.Network == networkConfig.Name {
+				chain = cr
+			}
+		}
+		wallets := make(map[string]*WalletReport)
+		var spent, burn float64
+		if chain != nil {
+			for _, wr := range chain.Wallets {
+				wallets[wr.Address] = wr
+				spent += wr.Spent
+				burn += wr.BurnRate
+			}
+		}
+
+		if networkConfig.MonthlyBudget != "" {
+			budget, err := strconv.ParseFloat(networkConfig.MonthlyBudget, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid monthly budget of %s: %w", networkConfig.Name, err)
+			}
+			budgets = append(budgets, BudgetStatus{
+				Network:   networkConfig.Name,
+				Coin:      networkConfig.Coin,
+				Spent:     spent,
+				Projected: spent + burn*remaining,
+				Budget:    budget,
+			})
+		}
+		for _, wallet := range networkConfig.Wallets {
+			if wallet.MonthlyBudget == "" {
+				continue
+			}
+			budget, err := strconv.ParseFloat(wallet.MonthlyBudget, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid monthly budget of %s: %w", wallet.Name, err)
+			}
+			status := BudgetStatus{
+				Network: networkConfig.Name,
+				Wallet:  wallet.Name,
+				Address: wallet.Address,
+				Coin:    networkConfig.Coin,
+				Budget:  budget,
+			}
+			if wr, ok := wallets[wallet.Address]; ok {
+				status.Spent = wr.Spent
+				status.Projected = wr.Spent + wr.BurnRate*remaining
+			}
+			budgets = append(budgets, status)
+		}
+	}
+	return budgets, nil
+}
+
+// alert once a month for every budget projected to be exceeded
+func checkBudgets(chainCfg *ChainConfig, store *stateStore) error {
+	now := time.Now().UTC()
+	samples, err := readHistory(chainCfg.historyPath(), monthStart(now))
+	if err != nil {
+		return err
+	}
+	budgets, err := buildBudgets(chainCfg, samples, now)
+	if err != nil {
+		return err
+	}
+
+	month := now.Format("2006-01")
+	for _, b := range budgets {
+		if !b.over() {
+			continue
+		}
+		var notify bool
+		err := store.update(func(st *AlertState) error {
+			ws := st.wallet(b.key())
+			notify = ws.BudgetAlerted != month && !ws.silenced(now)
+			ws.BudgetAlerted = month
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if notify {
+			sendAlert(chainCfg, Alert{
+				Kind:      alertBudget,
+				Severity:  severityCritical,
+				Network:   b.Network,
+				Wallet:    b.Wallet,
+				Address:   b.Address,
+				Coin:      b.Coin,
+				Spent:     chainCfg.Format.format(big.NewFloat(b.Spent)),
+				Projected: chainCfg.Format.format(big.NewFloat(b.Projected)),
+				Budget:    chainCfg.Format.format(big.NewFloat(b.Budget)),
+				Amount:    big.NewFloat(b.Projected),
+				Limit:     big.NewFloat(b.Budget),
+				Channels:  chainCfg.walletChannels(b.Network, b.Address),
+			})
+		}
+	}
+	return nil
+}
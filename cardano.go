@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// cardano apis, koios unless the network sets provider
+const (
+	cardanoKoios      = "koios"
+	cardanoBlockfrost = "blockfrost"
+)
+
+type BlockfrostAddressTotal struct {
+	ReceivedSum []BlockfrostAmount `json:"received_sum"`
+	SentSum     []BlockfrostAmount `json:"sent_sum"`
+}
+
+type BlockfrostAmount struct {
+	Unit     string `json:"unit"`
+	Quantity string `json:"quantity"`
+}
+
+type KoiosAccountInfo struct {
+	UTXO string `json:"utxo"`
+}
+
+// lovelace held by the payment addresses of a stake address, from
+// blockfrost or koios depending on the network provider
+func getCardanoBalance(ctx context.Context, networkConfig NetworkConfig, stakeAddress string) (*big.Int, error) {
+	key := os.Getenv(networkConfig.RPCKeyEnv)
+	switch networkConfig.Provider {
+	case "", cardanoKoios:
+	case cardanoBlockfrost:
+		var total BlockfrostAddressTotal
+		if err := cardanoRequest(ctx, http.MethodGet, networkConfig.RPC+"/accounts/"+url.PathEscape(stakeAddress)+"/addresses/total", nil, map[string]string{"project_id": key}, &total); err != nil {
+			return nil, err
+		}
+		balance := new(big.Int)
+		for _, a := range total.ReceivedSum {
+			if q, ok := new(big.Int).SetString(a.Quantity, 10); ok && a.Unit == "lovelace" {
+				balance.Add(balance, q)
+			}
+		}
+		for _, a := range total.SentSum {
+			if q, ok := new(big.Int).SetString(a.Quantity, 10); ok && a.Unit == "lovelace" {
+				balance.Sub(balance, q)
+			}
+		}
+		return balance, nil
+	default:
+		return nil, fmt.Errorf("unknown cardano provider %q", networkConfig.Provider)
+	}
+
+	headers := map[string]string{}
+	if key != "" {
+		headers["Authorization"] = "Bearer " + key
+	}
+	var infos []KoiosAccountInfo
+	body := map[string][]string{"_stake_addresses": {stakeAddress}}
+	if err := cardanoRequest(ctx, http.MethodPost, networkConfig.RPC+"/account_info", body, headers, &infos); err != nil {
+		return nil, err
+	}
+	if len(infos) == 0 {
+		return new(big.Int), nil
+	}
+	balance, ok := new(big.Int).SetString(infos[0].UTXO, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid cardano balance %q", infos[0].UTXO)
+	}
+	return balance, nil
+}
+
+func cardanoRequest(ctx context.Context, method, apiURL string, body any, headers map[string]string, result any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// named alert channel wallets can route their alerts to, keyed by
+// "<type>:<name>", e.g. "discord:ops" or "telegram:bridge-team"
+type ChannelConfig struct {
+	// webhook url of discord, slack, teams, google_chat and webhook channels
+	URL    string `json:"url,omitempty"`
+	URLEnv string `json:"url_env,omitempty"`
+	// telegram chat, the bot token is shared
+	ChatID string `json:"chat_id,omitempty"`
+	// matrix room on the configured homeserver
+	RoomID   string `json:"room_id,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+func (c ChannelConfig) url() string {
+	if c.URLEnv != "" {
+		return os.Getenv(c.URLEnv)
+	}
+	return c.URL
+}
+
+// sender of a named channel
+func (c ChannelConfig) sender(chainCfg *ChainConfig, name string) (func(alert Alert) error, error) {
+	kind, _, _ := strings.Cut(name, ":")
+	lang := c.Language
+	switch kind {
+	case "discord":
+		return func(alert Alert) error {
+			return sendDiscordAlert(c.url(), chainCfg.discordMessage(name, alert, lang))
+		}, nil
+	case "telegram":
+		return func(alert Alert) error {
+			return sendTelegramAlert(c.ChatID, withMentions(alert.Contact.telegramMentions(), chainCfg.alertText(name, alert, lang)))
+		}, nil
+	case "slack":
+		return func(alert Alert) error {
+			return sendSlackWebhook(c.url(), withMentions(alert.Contact.slackMentions(), slackMarkdown(chainCfg.alertText(name, alert, lang))))
+		}, nil
+	case "teams":
+		return func(alert Alert) error {
+			return sendTeamsAlert(c.url(), alert, lang)
+		}, nil
+	case "google_chat":
+		return func(alert Alert) error {
+			return sendGoogleChatAlert(c.url(), alert, lang)
+		}, nil
+	case "matrix":
+		if chainCfg.Matrix == nil {
+			return nil, fmt.Errorf("channel %s needs the matrix homeserver to be configured", name)
+		}
+		room := *chainCfg.Matrix
+		room.RoomID = c.RoomID
+		return func(alert Alert) error {
+			return sendMatrixAlert(&room, chainCfg.alertText(name, alert, lang))
+		}, nil
+	case "webhook":
+		return func(alert Alert) error {
+			return sendWebhooks([]string{c.url()}, alert)
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported channel type of %s", name)
+}
+
+// chat and paging sinks of the environment, such as opsgenie and twilio,
+// only get alerts of wallets without channels, the other integrations
+// record every alert
+func defaultRoute(fn func(alert Alert) error) func(alert Alert) error {
+	return func(alert Alert) error {
+		if len(alert.Channels) > 0 {
+			return nil
+		}
+		return fn(alert)
+	}
+}
+
+// notifiers of the named channels, each only sends alerts routed to it
+func channelNotifiers(chainCfg *ChainConfig) []notifier {
+	var ns []notifier
+	for name, c := range chainCfg.Channels {
+		send, err := c.sender(chainCfg, name)
+		if err != nil {
+			slog.Error("Invalid channel", "channel", name, "err", err)
+			continue
+		}
+		// webhooks retry each url and matrix each message under its
+		// transaction id on their own
+		switch {
+		case strings.HasPrefix(name, "webhook:"):
+			send = chainCfg.limit(name, send)
+		case strings.HasPrefix(name, "matrix:"):
+			send = chainCfg.limitChat(name, send)
+		default:
+			send = chainCfg.limitChat(name, retried(send))
+		}
+		ns = append(ns, notifierFunc(func(alert Alert) error {
+			if !slices.Contains(alert.Channels, name) {
+				return nil
+			}
+			return send(alert)
+		}))
+	}
+	return ns
+}
+
+// check that wallets only route to channels that exist
+func (c *ChainConfig) validateChannels() error {
+	for name, channel := range c.Channels {
+		if _, err := channel.sender(c, name); err != nil {
+			return err
+		}
+	}
+	for _, networkConfig := range c.Chains {
+		for _, step := range networkConfig.Escalation {
+			if err := c.knownChannels(step.Channels); err != nil {
+				return fmt.Errorf("%s: %w", networkConfig.Name, err)
+			}
+		}
+		for _, w := range networkConfig.Wallets {
+			channels := slices.Clone(w.Channels)
+			for _, step := range w.Escalation {
+				channels = append(channels, step.Channels...)
+			}
+			if err := c.knownChannels(channels); err != nil {
+				return fmt.Errorf("%s %s: %w", networkConfig.Name, w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *ChainConfig) knownChannels(names []string) error {
+	for _, name := range names {
+		if _, ok := c.Channels[name]; !ok {
+			return fmt.Errorf("unknown channel %s", name)
+		}
+	}
+	return nil
+}
+
+// channels of the wallet with the given address, nil if it has none
+func (c *ChainConfig) walletChannels(network, address string) []string {
+	_, w, err := c.findWallet(network, address)
+	if err != nil {
+		return nil
+	}
+	return w.Channels
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type profileReport struct {
+	name    string
+	wallets map[string]*WalletReport
+	coins   map[string]string
+}
+
+func (c *ChainConfig) profileName(path string) string {
+	if c.Profile != "" {
+		return c.Profile
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// compare lines up same-named wallets across profiles and flags divergence
+func compare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	since := fs.Duration("since", 7*24*time.Hour, "history used for burn rates")
+	tolerance := fs.Float64("tolerance", 2, "ratio between profiles considered divergent")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: compare [flags] wallets.json other-wallets.json...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return fmt.Errorf("at least two profiles are required")
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-*since)
+	var profiles []profileReport
+	var keys []string
+	seen := make(map[string]bool)
+	for _, path := range fs.Args() {
+		chainCfg, err := loadConfig(path)
+		if err != nil {
+			return err
+		}
+		samples, err := readHistory(chainCfg.historyPath(), from)
+		if err != nil {
+			return err
+		}
+		p := profileReport{
+			name:    chainCfg.profileName(path),
+			wallets: make(map[string]*WalletReport),
+			coins:   make(map[string]string),
+		}
+		for _, cr := range buildReport(samples, from, to).Chains {
+			for _, wr := range cr.Wallets {
+				key := cr.Network + "/" + wr.Wallet
+				p.wallets[key] = wr
+				p.coins[key] = cr.Coin
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+		profiles = append(profiles, p)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"Wallet"}
+	for _, p := range profiles {
+		header = append(header, p.name)
+	}
+	fmt.Fprintln(w, strings.Join(append(header, "Divergence"), "\t"))
+	for _, key := range keys {
+		row := []string{key}
+		for _, p := range profiles {
+			wr, ok := p.wallets[key]
+			if !ok {
+				row = append(row, "-")
+				continue
+			}
+			status := "ok"
+			if wr.Breached {
+				status = "BREACH"
+			}
+			row = append(row, fmt.Sprintf("%.4f %s (%.4f/day) %s", wr.Balance, p.coins[key], wr.BurnRate, status))
+		}
+		row = append(row, strings.Join(divergence(profiles, key, *tolerance), "; "))
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// differences of a wallet against the first profile that has it
+func divergence(profiles []profileReport, key string, tolerance float64) []string {
+	var base *WalletReport
+	var baseName string
+	var notes []string
+	for _, p := range profiles {
+		wr, ok := p.wallets[key]
+		if !ok {
+			notes = append(notes, "missing in "+p.name)
+			continue
+		}
+		if base == nil {
+			base, baseName = wr, p.name
+			continue
+		}
+		if wr.Breached != base.Breached {
+			notes = append(notes, fmt.Sprintf("breach status differs in %s", p.name))
+		}
+		if r := ratio(wr.BurnRate, base.BurnRate); r > tolerance {
+			notes = append(notes, fmt.Sprintf("burn rate x%.1f between %s and %s", r, baseName, p.name))
+		}
+		if wr.Threshold > 0 && base.Threshold > 0 {
+			if r := ratio(wr.Balance/wr.Threshold, base.Balance/base.Threshold); r > tolerance {
+				notes = append(notes, fmt.Sprintf("funding level x%.1f between %s and %s", r, baseName, p.name))
+			}
+		}
+	}
+	return notes
+}
+
+// ratio of the larger to the smaller value, zero if either is not positive
+func ratio(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return a / b
+}
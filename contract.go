@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+)
+
+// wallet kind of evm contracts holding funds, such as a gnosis safe
+const walletKindContract = "contract"
+
+const (
+	// getOwners()
+	safeOwnersSelector = "0xa0e67e2b"
+	// getThreshold()
+	safeThresholdSelector = "0xe75235b8"
+)
+
+// owners and signing threshold of a safe
+type SafeInfo struct {
+	Owners    []string
+	Threshold int64
+}
+
+func (s *SafeInfo) String() string {
+	return fmt.Sprintf("safe %d of %d owners", s.Threshold, len(s.Owners))
+}
+
+func ethCall(ctx context.Context, rpcURL, to, data string) ([]byte, error) {
+	call := map[string]string{"to": to, "data": data}
+	var result string
+	if err := callJSONRPC(ctx, rpcURL, "eth_call", []any{call, "latest"}, &result); err != nil {
+		return nil, err
+	}
+	res, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil || len(res)%32 != 0 {
+		return nil, fmt.Errorf("invalid eth_call result %q", result)
+	}
+	return res, nil
+}
+
+// 32 byte abi word at index i as an integer
+func abiWord(data []byte, i int) (*big.Int, error) {
+	if len(data) < (i+1)*32 {
+		return nil, fmt.Errorf("abi result too short")
+	}
+	return new(big.Int).SetBytes(data[i*32 : (i+1)*32]), nil
+}
+
+// owners and threshold of a safe, nil if the contract is not a safe
+func getSafeInfo(ctx context.Context, rpcURL, address string) (*SafeInfo, error) {
+	threshold, err := ethCall(ctx, rpcURL, address, safeThresholdSelector)
+	if err != nil && strings.Contains(err.Error(), "execution reverted") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(threshold) == 0 {
+		return nil, nil
+	}
+	t, err := abiWord(threshold, 0)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := ethCall(ctx, rpcURL, address, safeOwnersSelector)
+	if err != nil {
+		return nil, err
+	}
+	// dynamic address array: offset, length, then one word per owner
+	offset, err := abiWord(owners, 0)
+	if err != nil {
+		return nil, err
+	}
+	start := int(offset.Int64() / 32)
+	n, err := abiWord(owners, start)
+	if err != nil {
+		return nil, err
+	}
+	info := &SafeInfo{Threshold: t.Int64()}
+	for i := range int(n.Int64()) {
+		owner, err := abiWord(owners, start+1+i)
+		if err != nil {
+			return nil, err
+		}
+		info.Owners = append(info.Owners, fmt.Sprintf("0x%040x", owner))
+	}
+	return info, nil
+}
+
+// safe metadata and token holdings of a contract wallet, one line each
+func contractDetails(ctx context.Context, format NumberFormat, networkConfig NetworkConfig, w Wallet) []string {
+	var details []string
+	safe, err := getSafeInfo(ctx, networkConfig.RPC, w.Address)
+	switch {
+	case err != nil:
+		slog.Error("Fetching safe info failed", "chain", networkConfig.Name, "wallet", w.Name, "err", err)
+	case safe != nil:
+		details = append(details, safe.String())
+	}
+	for _, token := range networkConfig.walletTokens(w) {
+		raw, err := getERC20Balance(ctx, networkConfig.RPC, token.Contract, w.Address)
+		if err != nil {
+			slog.Error("Fetching token balance failed", "chain", networkConfig.Name, "wallet", w.Name, "token", token.Symbol, "err", err)
+			continue
+		}
+		details = append(details, format.format(toDecimalUnit(raw, token.Decimals))+" "+token.Symbol)
+	}
+	return details
+}
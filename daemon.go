@@ -0,0 +1,145 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// shortest interval between checks, anything shorter hammers the rpcs
+const minInterval = time.Second
+
+// wallets of a network checked on the same schedule
+type schedule struct {
+	network NetworkConfig
+	// interval or cron expression the schedule follows
+	spec string
+	when cron.Schedule
+}
+
+// schedule of a wallet in daemon mode: its own interval, else the cron
+// schedule or interval of its network, else the -interval flag
+func (n NetworkConfig) schedule(w Wallet, fallback time.Duration) (string, cron.Schedule, error) {
+	if w.Interval == "" && n.Schedule != "" {
+		when, err := cron.ParseStandard(n.Schedule)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid schedule %q of %s: %w", n.Schedule, n.Name, err)
+		}
+		return n.Schedule, when, nil
+	}
+	every := fallback
+	if interval := cmp.Or(w.Interval, n.Interval); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < minInterval {
+			return "", nil, fmt.Errorf("invalid interval %q of %s %s", interval, n.Name, w.Name)
+		}
+		every = d
+	}
+	return "every " + every.String(), cron.Every(every), nil
+}
+
+// time between the upcoming runs of a schedule
+func period(when cron.Schedule) time.Duration {
+	next := when.Next(time.Now())
+	return when.Next(next).Sub(next)
+}
+
+// split each network into the groups of wallets sharing a schedule
+func (c *ChainConfig) schedules(fallback time.Duration) []schedule {
+	var schedules []schedule
+	for _, networkConfig := range c.Chains {
+		var groups []schedule
+		index := make(map[string]int)
+		add := func(w *Wallet) {
+			if w == nil {
+				w = &Wallet{}
+			}
+			spec, when, _ := networkConfig.schedule(*w, fallback)
+			i, ok := index[spec]
+			if !ok {
+				network := networkConfig
+				network.Wallets = nil
+				i = len(groups)
+				index[spec] = i
+				groups = append(groups, schedule{network: network, spec: spec, when: when})
+			}
+			if w.Address != "" {
+				groups[i].network.Wallets = append(groups[i].network.Wallets, *w)
+			}
+		}
+		for _, w := range networkConfig.Wallets {
+			add(&w)
+		}
+		// networks without wallets may still have fee sharing checks
+		if len(groups) == 0 {
+			add(nil)
+		}
+		// the checks of the network rather than its wallets run in the most
+		// frequent group only
+		first := 0
+		for i, g := range groups {
+			if period(g.when) < period(groups[first].when) {
+				first = i
+			}
+		}
+		for i := range groups {
+			if i != first {
+				groups[i].network.FeeSharing = nil
+			}
+		}
+		schedules = append(schedules, groups...)
+	}
+	return schedules
+}
+
+// check the balances on each schedule until ctx is cancelled, each run has
+// its own timeout and the alert state stays cached between runs. On shutdown
+// the runs in flight stop checking, deliver their alerts and record their
+// results, the state is written on every change
+func runDaemon(ctx context.Context, m *monitor) {
+	var wg sync.WaitGroup
+	if *listen != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveHealth(ctx, m)
+		}()
+	}
+	if *watchBlocks && m.startWatchers(ctx, &wg) == 0 {
+		slog.Warn("No network has a ws endpoint to watch")
+	}
+	if *profiling {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveProfiles(ctx)
+		}()
+	}
+	schedules := m.chainCfg.schedules(*interval)
+	for _, s := range schedules {
+		alertmanagerEndsAfter = max(alertmanagerEndsAfter, 3*period(s.when))
+	}
+	for _, s := range schedules {
+		slog.Info("Scheduling checks", "chain", s.network.Name, "wallets", len(s.network.Wallets), "schedule", s.spec)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := time.Now()
+				check(ctx, m, []NetworkConfig{s.network})
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Until(s.when.Next(start))):
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	slog.Info("Shut down")
+}
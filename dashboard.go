@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+type GrafanaDashboard struct {
+	UID           string            `json:"uid,omitempty"`
+	Title         string            `json:"title"`
+	Tags          []string          `json:"tags"`
+	Timezone      string            `json:"timezone"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Refresh       string            `json:"refresh"`
+	Time          GrafanaTimeRange  `json:"time"`
+	Templating    GrafanaTemplating `json:"templating"`
+	Panels        []GrafanaPanel    `json:"panels"`
+}
+
+type GrafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type GrafanaTemplating struct {
+	List []GrafanaVariable `json:"list"`
+}
+
+type GrafanaVariable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+type GrafanaDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type GrafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type GrafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+type GrafanaThresholdStep struct {
+	Color string `json:"color"`
+	// nil for the base step
+	Value *float64 `json:"value"`
+}
+
+type GrafanaFieldConfig struct {
+	Defaults struct {
+		Unit   string `json:"unit,omitempty"`
+		Custom struct {
+			ThresholdsStyle struct {
+				Mode string `json:"mode"`
+			} `json:"thresholdsStyle"`
+		} `json:"custom"`
+		Thresholds struct {
+			Mode  string                 `json:"mode"`
+			Steps []GrafanaThresholdStep `json:"steps"`
+		} `json:"thresholds"`
+	} `json:"defaults"`
+	Overrides []any `json:"overrides"`
+}
+
+type GrafanaPanel struct {
+	ID          int                 `json:"id"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	GridPos     GrafanaGridPos      `json:"gridPos"`
+	Collapsed   bool                `json:"collapsed,omitempty"`
+	Datasource  *GrafanaDatasource  `json:"datasource,omitempty"`
+	Targets     []GrafanaTarget     `json:"targets,omitempty"`
+	FieldConfig *GrafanaFieldConfig `json:"fieldConfig,omitempty"`
+}
+
+const (
+	dashboardPanelWidth  = 8
+	dashboardPanelHeight = 8
+	// grafana's grid is 24 columns wide
+	dashboardColumns = 24 / dashboardPanelWidth
+)
+
+// balance panel of a wallet with its warning and critical thresholds drawn
+// as lines, red below critical, orange below warning
+func walletPanel(networkConfig NetworkConfig, w Wallet) GrafanaPanel {
+	panel := GrafanaPanel{
+		Type:       "timeseries",
+		Title:      fmt.Sprintf("%s (%s)", w.Name, networkConfig.Coin),
+		Datasource: &GrafanaDatasource{Type: "prometheus", UID: "${datasource}"},
+		Targets: []GrafanaTarget{{
+			RefID:        "A",
+			Expr:         fmt.Sprintf("balance_tracker_balance{network=%q,address=%q}", networkConfig.Name, w.Address),
+			LegendFormat: w.Name,
+		}},
+		FieldConfig: &GrafanaFieldConfig{Overrides: []any{}},
+	}
+	defaults := &panel.FieldConfig.Defaults
+	defaults.Unit = "suffix: " + networkConfig.Coin
+	defaults.Custom.ThresholdsStyle.Mode = "line"
+	defaults.Thresholds.Mode = "absolute"
+	steps := []GrafanaThresholdStep{{Color: "red"}}
+	critical, warning := networkConfig.thresholds(w)
+	if critical != nil {
+		v, _ := critical.Float64()
+		steps = append(steps, GrafanaThresholdStep{Color: "orange", Value: &v})
+	}
+	if warning != nil {
+		v, _ := warning.Float64()
+		steps = append(steps, GrafanaThresholdStep{Color: "green", Value: &v})
+	} else {
+		steps[len(steps)-1].Color = "green"
+	}
+	defaults.Thresholds.Steps = steps
+	return panel
+}
+
+// one row per network with a panel per alerting wallet
+func buildDashboard(chainCfg *ChainConfig, title, uid string) GrafanaDashboard {
+	d := GrafanaDashboard{
+		UID:           uid,
+		Title:         title,
+		Tags:          []string{"balance-tracker"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Refresh:       "1m",
+		Time:          GrafanaTimeRange{From: "now-7d", To: "now"},
+		Templating: GrafanaTemplating{List: []GrafanaVariable{{
+			Name:  "datasource",
+			Label: "Data source",
+			Type:  "datasource",
+			Query: "prometheus",
+		}}},
+	}
+	id, y := 1, 0
+	for _, networkConfig := range chainCfg.Chains {
+		d.Panels = append(d.Panels, GrafanaPanel{
+			ID:      id,
+			Type:    "row",
+			Title:   networkConfig.Name,
+			GridPos: GrafanaGridPos{X: 0, Y: y, W: 24, H: 1},
+		})
+		id, y = id+1, y+1
+		n := 0
+		for _, w := range networkConfig.Wallets {
+			// only alerting wallets are checked and exported
+			if !w.Alert {
+				continue
+			}
+			panel := walletPanel(networkConfig, w)
+			panel.ID = id
+			panel.GridPos = GrafanaGridPos{
+				X: n % dashboardColumns * dashboardPanelWidth,
+				Y: y + n/dashboardColumns*dashboardPanelHeight,
+				W: dashboardPanelWidth,
+				H: dashboardPanelHeight,
+			}
+			d.Panels = append(d.Panels, panel)
+			id, n = id+1, n+1
+		}
+		y += (n + dashboardColumns - 1) / dashboardColumns * dashboardPanelHeight
+	}
+	return d
+}
+
+// dashboard prints a grafana dashboard of the configured wallets' balances
+func dashboard(args []string) error {
+	fs := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	title := fs.String("title", "Wallet balances", "title of the dashboard")
+	uid := fs.String("uid", "balance-tracker", "uid of the dashboard, imports replace the dashboard of the same uid")
+	out := fs.String("out", "", "file to write the dashboard to instead of stdout")
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(buildDashboard(chainCfg, *title, *uid), "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Println(string(content))
+		return nil
+	}
+	if err := os.WriteFile(*out, content, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Dashboard written to %s\n", *out)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+var (
+	datadogAPIKey = os.Getenv("DD_API_KEY")
+	datadogSite   = os.Getenv("DD_SITE")
+)
+
+type DatadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+	Tags           []string `json:"tags"`
+}
+
+// emit an event when a wallet starts breaching or recovers
+func sendDatadogEvent(alert Alert) error {
+	event := DatadogEvent{
+		AggregationKey: alert.key(),
+		SourceTypeName: "balance-tracker",
+		Tags:           []string{"chain:" + alert.Network, "wallet:" + alert.Wallet, "address:" + alert.Address, "severity:" + alert.Severity},
+	}
+	switch {
+	case alert.Kind == alertRecovery:
+		event.Title = fmt.Sprintf("%s %s %s recovered", alert.Network, alert.Wallet, alert.subject())
+		event.AlertType = "success"
+	case alert.New:
+		event.Title = fmt.Sprintf("%s %s %s below threshold", alert.Network, alert.Wallet, alert.subject())
+		event.AlertType = "error"
+	default:
+		return nil
+	}
+	event.Text = fmt.Sprintf("Balance: %s %s\nThreshold: %s %s", alert.Balance, alert.Coin, alert.Threshold, alert.Coin)
+
+	jsonMsg, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	site := datadogSite
+	if site == "" {
+		site = "datadoghq.com"
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api."+site+"/api/v1/events", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", datadogAPIKey)
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
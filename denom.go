@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// origin of an ibc voucher denom
+type DenomTrace struct {
+	BaseDenom string `json:"base_denom"`
+	Path      string `json:"path"`
+	ChainID   string `json:"chain_id,omitempty"`
+}
+
+func (d DenomTrace) String() string {
+	if d.ChainID != "" {
+		return fmt.Sprintf("%s (%s)", d.BaseDenom, d.ChainID)
+	}
+	return fmt.Sprintf("%s (%s)", d.BaseDenom, d.Path)
+}
+
+func isIBCDenom(denom string) bool {
+	return strings.HasPrefix(strings.ToLower(denom), "ibc/")
+}
+
+// resolve the base denom and origin chain of an ibc denom through the lcd
+func getDenomTrace(ctx context.Context, lcd, denom string) (DenomTrace, error) {
+	hash := denom[len("ibc/"):]
+	var res struct {
+		DenomTrace DenomTrace `json:"denom_trace"`
+	}
+	if err := getJSON(ctx, lcd+"/ibc/apps/transfer/v1/denom_traces/"+hash, &res); err != nil {
+		return DenomTrace{}, err
+	}
+	trace := res.DenomTrace
+
+	// the channel the voucher came through tells the counterparty chain
+	parts := strings.Split(trace.Path, "/")
+	if len(parts) >= 2 {
+		var cs struct {
+			IdentifiedClientState struct {
+				ClientState struct {
+					ChainID string `json:"chain_id"`
+				} `json:"client_state"`
+			} `json:"identified_client_state"`
+		}
+		apiURL := fmt.Sprintf("%s/ibc/core/channel/v1/channels/%s/ports/%s/client_state", lcd, parts[1], parts[0])
+		if err := getJSON(ctx, apiURL, &cs); err == nil {
+			trace.ChainID = cs.IdentifiedClientState.ClientState.ChainID
+		}
+	}
+	return trace, nil
+}
+
+// readable coin name of a network, ibc denoms are resolved once and
+// cached in the state since their traces never change
+func (m *monitor) coin(networkConfig NetworkConfig) string {
+	denom := networkConfig.denom()
+	if networkConfig.Type != "cosmos" || !isIBCDenom(denom) {
+		return networkConfig.Coin
+	}
+	st, err := m.store.load()
+	if err != nil {
+		slog.Error("Loading denom traces failed", "err", err)
+		return networkConfig.Coin
+	}
+	if trace, ok := st.DenomTraces[denom]; ok {
+		return trace.String()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	trace, err := getDenomTrace(ctx, networkConfig.RPC, denom)
+	if err != nil {
+		slog.Error("Resolving denom failed", "chain", networkConfig.Name, "denom", denom, "err", err)
+		return networkConfig.Coin
+	}
+	err = m.store.update(func(st *AlertState) error {
+		if st.DenomTraces == nil {
+			st.DenomTraces = make(map[string]DenomTrace)
+		}
+		st.DenomTraces[denom] = trace
+		return nil
+	})
+	if err != nil {
+		slog.Error("Caching denom trace failed", "denom", denom, "err", err)
+	}
+	return trace.String()
+}
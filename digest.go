@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// order of alerts in a digest, most severe first
+func digestRank(a Alert) int {
+	switch {
+	case a.Kind == alertBreach && a.Severity == severityCritical:
+		return 0
+	case a.Kind == alertBreach:
+		return 1
+	case a.Kind == alertBudget:
+		return 2
+	}
+	return 3
+}
+
+// group alerts into a single one, the digest is critical and empty if any of
+// its alerts is
+func digestAlert(alerts []Alert) Alert {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return digestRank(alerts[i]) < digestRank(alerts[j])
+	})
+	digest := Alert{Kind: alertDigest, Severity: severityWarning, Alerts: alerts, Channels: alerts[0].Channels}
+	for _, a := range alerts {
+		if a.Kind == alertBreach && a.Severity == severityCritical {
+			digest.Severity = severityCritical
+		}
+		digest.Empty = digest.Empty || a.Empty
+		digest.Contact = digest.Contact.merge(a.Contact)
+	}
+	return digest
+}
+
+// mentions of both contacts, each once
+func (c Contact) merge(o Contact) Contact {
+	dedup := func(a, b []string) []string {
+		ids := slices.Concat(a, b)
+		var out []string
+		seen := make(map[string]bool)
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+		return out
+	}
+	return Contact{
+		DiscordRoles: dedup(c.DiscordRoles, o.DiscordRoles),
+		DiscordUsers: dedup(c.DiscordUsers, o.DiscordUsers),
+		Telegram:     dedup(c.Telegram, o.Telegram),
+		Slack:        dedup(c.Slack, o.Slack),
+	}
+}
+
+// one line summary of a digest, e.g. 3 alerts: 2 critical, 1 recovered
+func (a Alert) digestHeader(lang string) string {
+	if len(a.Alerts) == 0 {
+		return fmt.Sprintf("🔇 **%d %s**", a.Suppressed, tr(lang, "suppressed"))
+	}
+	counts := make(map[string]int)
+	for _, alert := range a.Alerts {
+		switch {
+		case alert.Kind == alertRecovery:
+			counts["recovered"]++
+		case alert.Kind == alertBudget:
+			counts["budget"]++
+		default:
+			counts[alert.Severity]++
+		}
+	}
+	var parts []string
+	for _, key := range []string{severityCritical, severityWarning, "budget", "recovered"} {
+		if counts[key] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[key], tr(lang, key)))
+		}
+	}
+	return fmt.Sprintf("📋 **%d %s**: %s", len(a.Alerts), tr(lang, "alerts"), strings.Join(parts, ", "))
+}
+
+// header followed by a line per alert
+func (a Alert) digestMessage(lang string, line func(Alert, string) string) string {
+	var sb strings.Builder
+	sb.WriteString(a.digestHeader(lang) + "\n\n")
+	for _, alert := range a.Alerts {
+		sb.WriteString(strings.TrimRight(line(alert, lang), "\n") + "\n")
+	}
+	return sb.String() + "\n"
+}
+
+// single line markdown version of an alert
+func (a Alert) line(lang string) string {
+	switch {
+	case a.Kind == alertBudget:
+		return fmt.Sprintf("💸 **%s** %s: %s %s %s / %s %s", a.Network, tr(lang, "budget_alert"), tr(lang, "projected"), a.Projected, a.Coin, a.Budget, a.Coin)
+	case a.Check == checkEndpoint:
+		return fmt.Sprintf("%s **%s** %s: %s", a.emoji(), a.Network, a.heading(lang), a.Target)
+	case a.Kind == alertRecovery:
+		return fmt.Sprintf("%s **%s** %s – %s: %s %s", a.emoji(), a.Network, a.Wallet, a.heading(lang), a.Balance, a.Coin)
+	}
+	return fmt.Sprintf("%s **%s** %s – %s: %s %s < %s %s (%s)", a.emoji(), a.Network, a.Wallet, a.heading(lang), a.Balance, a.Coin, a.Threshold, a.Coin, a.addressLink())
+}
+
+// integrations tracking each alert on its own get the alerts of a digest one
+// by one, operational alerts about endpoints stay in chat
+func perAlert(fn func(alert Alert) error) func(alert Alert) error {
+	return func(alert Alert) error {
+		if alert.Kind != alertDigest {
+			if alert.Check == checkEndpoint {
+				return nil
+			}
+			return fn(alert)
+		}
+		var failed error
+		for _, a := range alert.Alerts {
+			if a.Check == checkEndpoint {
+				continue
+			}
+			if err := fn(a); err != nil {
+				failed = err
+			}
+		}
+		return failed
+	}
+}
+
+// send the collected alerts as one digest per route
+func sendDigests(chainCfg *ChainConfig, alerts []Alert) {
+	var routes []string
+	grouped := make(map[string][]Alert)
+	for _, a := range alerts {
+		route := strings.Join(a.Channels, ",")
+		if _, ok := grouped[route]; !ok {
+			routes = append(routes, route)
+		}
+		grouped[route] = append(grouped[route], a)
+	}
+	for _, route := range routes {
+		if len(grouped[route]) == 1 {
+			sendAlert(chainCfg, grouped[route][0])
+			continue
+		}
+		sendAlert(chainCfg, digestAlert(grouped[route]))
+	}
+}
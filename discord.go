@@ -0,0 +1,77 @@
+package main
+
+import (
+	"time"
+)
+
+// discord limits embeds to 25 fields
+const discordMaxFields = 25
+
+type DiscordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+func discordColor(alert Alert) int {
+	switch {
+	case alert.Kind == alertRecovery:
+		return 0x2ecc71
+	case alert.Kind == alertBudget:
+		return 0xe67e22
+	case alert.Severity == severityWarning:
+		return 0xf1c40f
+	}
+	return 0xe74c3c
+}
+
+// embed of an alert with its details as fields, linking the explorer, a
+// channel with a template gets the rendered text instead of the fields
+func (c *ChainConfig) discordMessage(channel string, alert Alert, lang string) DiscordMessage {
+	heading, facts := alert.facts(lang)
+	// digest headers come with their own emoji
+	if alert.Kind != alertDigest {
+		heading = alert.emoji() + " " + heading
+	}
+	embed := DiscordEmbed{
+		Title:     heading,
+		URL:       alert.explorerURL(),
+		Color:     discordColor(alert),
+		Footer:    &DiscordEmbedFooter{Text: "balance tracker"},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if c.template(channel) != nil {
+		embed.Description = c.alertText(channel, alert, lang)
+	} else {
+		if alert.Kind != alertDigest {
+			embed.Fields = append(embed.Fields, DiscordEmbedField{Name: tr(lang, "chain"), Value: alert.Network, Inline: true})
+		}
+		for _, f := range facts {
+			if len(embed.Fields) == discordMaxFields {
+				break
+			}
+			// field names must not be empty
+			name := f.Title
+			if name == "" {
+				name = "\u200b"
+			}
+			embed.Fields = append(embed.Fields, DiscordEmbedField{Name: name, Value: f.Value, Inline: len(f.Value) <= 24})
+		}
+	}
+	// mentions in embeds do not ping, they go into the content
+	return DiscordMessage{Content: alert.Contact.discordMentions(), Embeds: []DiscordEmbed{embed}}
+}
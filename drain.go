@@ -0,0 +1,135 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// check name of alerts about wallets running out at their current spend rate
+const checkDrain = "drain"
+
+// history the drain rate is measured over, the report's burn rate needs at
+// least an hour of it
+const drainWindow = 24 * time.Hour
+
+// how soon a wallet may run out at its drain rate before it alerts, zero if
+// drain detection is off
+func (n NetworkConfig) drainHorizon(w Wallet) (time.Duration, error) {
+	horizon := cmp.Or(w.DrainHorizon, n.DrainHorizon)
+	if horizon == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(horizon)
+	if err != nil {
+		return 0, fmt.Errorf("invalid drain horizon of %s: %w", w.Name, err)
+	}
+	return d, nil
+}
+
+// samples of a wallet within the drain window, the window is read from the
+// history once and then kept up to date by record
+func (m *monitor) drainSamples(key string, now time.Time) []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.drain == nil {
+		m.drain = make(map[string][]Sample)
+		samples, err := readHistory(m.chainCfg.historyPath(), now.Add(-drainWindow))
+		if err != nil {
+			slog.Error("Reading history failed", "err", err)
+		}
+		for _, s := range samples {
+			m.drain[s.key()] = append(m.drain[s.key()], s)
+		}
+	}
+	window := m.drain[key]
+	for len(window) > 0 && window[0].Time.Before(now.Add(-drainWindow)) {
+		window = window[1:]
+	}
+	m.drain[key] = window
+	return slices.Clone(window)
+}
+
+// spend per hour of a wallet over the samples and its current balance, top
+// ups are left out, zero while the history is shorter than an hour so that
+// a fee between two rechecks seconds apart is no drain
+func drainRate(samples []Sample, key string, balance float64, now time.Time) float64 {
+	var first time.Time
+	var spent, prev float64
+	for _, s := range samples {
+		if s.key() != key {
+			continue
+		}
+		if first.IsZero() {
+			first = s.Time
+		} else if d := s.balance() - prev; d < 0 {
+			spent -= d
+		}
+		prev = s.balance()
+	}
+	if first.IsZero() {
+		return 0
+	}
+	if d := balance - prev; d < 0 {
+		spent -= d
+	}
+	return burnRate(spent, now.Sub(first)) / 24
+}
+
+// alert when the balance runs out within the drain horizon at the rate it
+// dropped over the last day, even while it is above threshold
+func (m *monitor) checkDrain(networkConfig NetworkConfig, r WalletBalance) {
+	horizon, err := networkConfig.drainHorizon(r.Wallet)
+	if err != nil {
+		slog.Error("Invalid drain horizon", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+		return
+	}
+	if horizon == 0 {
+		return
+	}
+	now := time.Now().UTC()
+	key := walletKey(networkConfig.Name, r.Wallet.Address)
+	balance, _ := r.Balance.Float64()
+	perHour := drainRate(m.drainSamples(key, now), key, balance, now)
+	var hoursLeft float64
+	var breached bool
+	if perHour > 0 {
+		hoursLeft = balance / perHour
+		breached = hoursLeft < horizon.Hours()
+	}
+
+	critical, _ := networkConfig.thresholds(r.Wallet)
+	alert := Alert{
+		Check:     checkDrain,
+		Severity:  severityCritical,
+		Network:   networkConfig.Name,
+		Wallet:    r.Wallet.Name,
+		Address:   r.Wallet.Address,
+		Balance:   m.chainCfg.Format.format(r.Balance),
+		Threshold: m.chainCfg.Format.format(critical),
+		Amount:    r.Balance,
+		Limit:     critical,
+		RawAmount: r.Raw,
+		Coin:      m.coin(networkConfig),
+		Explorer:  networkConfig.Explorer,
+		Contact:   m.chainCfg.contact(networkConfig, r.Wallet.Owner, severityCritical),
+		Channels:  r.Wallet.Channels,
+	}
+	if breached {
+		runsOut := time.Duration(hoursLeft * float64(time.Hour)).Round(time.Minute)
+		alert.Detail = fmt.Sprintf("draining %.4f %s/h, empty in %s", perHour, alert.Coin, runsOut)
+	}
+	rule, err := networkConfig.breachRule(r.Wallet)
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, breached, rule)
+	if err != nil {
+		slog.Error("Recording alert state failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+	}
+	if notify {
+		m.notify(alert)
+	}
+}
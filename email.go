@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	smtpHost     = os.Getenv("SMTP_HOST")
+	smtpPort     = os.Getenv("SMTP_PORT")
+	smtpUsername = os.Getenv("SMTP_USERNAME")
+	smtpPassword = os.Getenv("SMTP_PASSWORD")
+	smtpFrom     = os.Getenv("SMTP_FROM")
+	// comma separated recipients
+	smtpTo = os.Getenv("SMTP_TO")
+	// "tls" to connect over implicit tls, usually on port 465, starttls is used otherwise when offered
+	smtpTLS = os.Getenv("SMTP_TLS")
+)
+
+func emailConfigured() bool {
+	return smtpHost != "" && smtpFrom != "" && smtpTo != ""
+}
+
+// send a single email to the configured recipients
+func sendEmail(subject, contentType, body string) error {
+	port := smtpPort
+	if port == "" {
+		port = "587"
+	}
+	addr := net.JoinHostPort(smtpHost, port)
+	to := strings.Split(smtpTo, ",")
+	for i := range to {
+		to[i] = strings.TrimSpace(to[i])
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", smtpFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\nContent-Type: %s; charset=utf-8\r\n\r\n", contentType)
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if smtpUsername != "" {
+		auth = smtp.PlainAuth("", smtpUsername, smtpPassword, smtpHost)
+	}
+	// the whole session shares the deadline, a hung server must not hold
+	// the deliveries
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if smtpTLS == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: smtpHost})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	c, err := smtp.NewClient(conn, smtpHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if smtpTLS != "tls" {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: smtpHost}); err != nil {
+				return err
+			}
+		}
+	}
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(smtpFrom); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// plain text version of the markdown chat message
+func plainText(message string) string {
+	message = markdownLink.ReplaceAllString(message, "$1 ($2)")
+	return strings.ReplaceAll(message, "**", "")
+}
+
+func sendEmailAlert(alert Alert, message, lang string) error {
+	subject := alert.title()
+	if alert.Kind == alertBudget {
+		subject = fmt.Sprintf("%s %s", alert.Network, tr(lang, "budget_alert"))
+	}
+	return sendEmail(subject, "text/plain", plainText(message))
+}
+
+var emailSummaryTemplate = template.Must(template.New("summary").Parse(`<html>
+<body style="font-family: sans-serif">
+<h2>Balance check {{.Time}}</h2>
+<table style="border-collapse: collapse">
+<tr><th align="left">Network</th><th align="left">Wallet</th><th align="right">Balance</th><th align="right">Threshold</th></tr>
+{{range .Samples}}
+<tr{{if .Breached}} style="background: #fde2e2"{{end}}><td>{{.Network}}</td><td>{{.Wallet}}</td><td align="right">{{.Balance}} {{.Coin}}</td><td align="right">{{.Threshold}} {{.Coin}}</td></tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// email an html table of the balances checked in a run
+func sendEmailSummary(samples []Sample) error {
+	if !emailConfigured() {
+		return fmt.Errorf("SMTP_HOST, SMTP_FROM and SMTP_TO must be set")
+	}
+	var buf bytes.Buffer
+	data := struct {
+		Time    string
+		Samples []Sample
+	}{time.Now().UTC().Format(time.RFC1123), samples}
+	if err := emailSummaryTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	breached := 0
+	for _, s := range samples {
+		if s.Breached {
+			breached++
+		}
+	}
+	subject := fmt.Sprintf("Balance summary: %d of %d wallets below threshold", breached, len(samples))
+	return sendEmail(subject, "text/html", buf.String())
+}
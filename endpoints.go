@@ -0,0 +1,302 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	// weight of the newest observation in the moving averages
+	endpointAlpha = 0.2
+	// scores below this suggest rotating the endpoint out
+	endpointMinScore = 50.0
+)
+
+// moving averages of an rpc endpoint's quality
+type EndpointStats struct {
+	Network    string    `json:"network"`
+	Checks     int       `json:"checks"`
+	Errors     int       `json:"errors"`
+	ErrorRate  float64   `json:"error_rate"`
+	LatencyMs  float64   `json:"latency_ms"`
+	StalenessS float64   `json:"staleness_s"`
+	LastError  string    `json:"last_error,omitempty"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+func ewma(avg, v float64, first bool) float64 {
+	if first {
+		return v
+	}
+	return endpointAlpha*v + (1-endpointAlpha)*avg
+}
+
+// health score from 0 to 100, penalizing errors, latency above a second
+// and heads older than two minutes
+func (e *EndpointStats) score() float64 {
+	score := 100 * (1 - e.ErrorRate)
+	if e.LatencyMs > 1000 {
+		score *= 1000 / e.LatencyMs
+	}
+	if e.StalenessS > 120 {
+		score *= 120 / e.StalenessS
+	}
+	return score
+}
+
+func (e *EndpointStats) suggestion() string {
+	if e.score() >= endpointMinScore {
+		return ""
+	}
+	var reasons []string
+	if e.ErrorRate > 0.1 {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% errors", e.ErrorRate*100))
+	}
+	if e.LatencyMs > 1000 {
+		reasons = append(reasons, fmt.Sprintf("%.0fms latency", e.LatencyMs))
+	}
+	if e.StalenessS > 120 {
+		reasons = append(reasons, fmt.Sprintf("head %.0fs behind", e.StalenessS))
+	}
+	return "consider replacing: " + strings.Join(reasons, ", ")
+}
+
+var errProbeUnsupported = errors.New("head probe not supported")
+
+type endpointProbe struct {
+	latency time.Duration
+	// negative if the time of the head is unknown
+	staleness time.Duration
+	err       error
+}
+
+// time a head query against the endpoint and measure how old the head is
+func probeEndpoint(ctx context.Context, networkConfig NetworkConfig, endpoint string) endpointProbe {
+	start := time.Now()
+	head, err := latestBlockTime(ctx, networkConfig, endpoint)
+	p := endpointProbe{latency: time.Since(start), staleness: -1, err: err}
+	if err == nil && !head.IsZero() {
+		p.staleness = max(time.Since(head), 0)
+	}
+	return p
+}
+
+// time of the endpoint's head, zero if the chain does not know it
+func latestBlockTime(ctx context.Context, networkConfig NetworkConfig, endpoint string) (time.Time, error) {
+	switch networkConfig.Type {
+	case "evm":
+		var block struct {
+			Timestamp string `json:"timestamp"`
+		}
+		if err := callJSONRPC(ctx, endpoint, "eth_getBlockByNumber", []any{"latest", false}, &block); err != nil {
+			return time.Time{}, err
+		}
+		ts, err := strconv.ParseInt(strings.TrimPrefix(block.Timestamp, "0x"), 16, 64)
+		return time.Unix(ts, 0), err
+	case "icon":
+		var block struct {
+			TimeStamp int64 `json:"time_stamp"`
+		}
+		if err := callJSONRPC(ctx, endpoint, "icx_getLastBlock", nil, &block); err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMicro(block.TimeStamp), nil
+	case "cosmos":
+		var res struct {
+			Block struct {
+				Header struct {
+					Time time.Time `json:"time"`
+				} `json:"header"`
+			} `json:"block"`
+		}
+		err := getJSON(ctx, endpoint+"/cosmos/base/tendermint/v1beta1/blocks/latest", &res)
+		return res.Block.Header.Time, err
+	case "solana":
+		var slot uint64
+		if err := callJSONRPC(ctx, endpoint, "getSlot", nil, &slot); err != nil {
+			return time.Time{}, err
+		}
+		// null for slots that were skipped or whose time was pruned
+		var ts *int64
+		if err := callJSONRPC(ctx, endpoint, "getBlockTime", []any{slot}, &ts); err != nil || ts == nil {
+			return time.Time{}, err
+		}
+		return time.Unix(*ts, 0), nil
+	}
+	return time.Time{}, errProbeUnsupported
+}
+
+// fold a probe and the share of failed wallet fetches into the endpoint stats
+func recordEndpoint(store *stateStore, network, endpoint string, p endpointProbe, failed float64) error {
+	return store.update(func(st *AlertState) error {
+		if st.Endpoints == nil {
+			st.Endpoints = make(map[string]*EndpointStats)
+		}
+		e, ok := st.Endpoints[endpoint]
+		if !ok {
+			e = &EndpointStats{Network: network}
+			st.Endpoints[endpoint] = e
+		}
+		first := e.Checks == 0
+		e.Checks++
+		e.LastSeen = time.Now().UTC()
+
+		errRate := failed
+		switch {
+		case errors.Is(p.err, errProbeUnsupported):
+			// only the wallet fetches tell about the endpoint
+		case p.err != nil:
+			errRate = 1
+			e.Errors++
+			e.LastError = p.err.Error()
+		default:
+			e.LatencyMs = ewma(e.LatencyMs, float64(p.latency.Milliseconds()), first)
+			if p.staleness >= 0 {
+				e.StalenessS = ewma(e.StalenessS, p.staleness.Seconds(), first)
+			}
+		}
+		e.ErrorRate = ewma(e.ErrorRate, errRate, first)
+		return nil
+	})
+}
+
+// check name of operational alerts about failing rpc endpoints
+const checkEndpoint = "endpoint"
+
+// consecutive failed checks before an endpoint alerts
+const defaultEndpointFailures = 3
+
+// record the endpoint stats and alert once the endpoint failed repeatedly,
+// it fails when its head cannot be fetched or no wallet could be fetched;
+// an open circuit breaker alerts right away and once per cool-down
+func (m *monitor) checkEndpoint(networkConfig NetworkConfig, endpoint string, p endpointProbe, failed float64) {
+	if err := recordEndpoint(m.store, networkConfig.Name, endpoint, p, failed); err != nil {
+		slog.Error("Recording endpoint stats failed", "chain", networkConfig.Name, "endpoint", endpoint, "err", err)
+	}
+	alert := Alert{
+		Check:    checkEndpoint,
+		Target:   endpoint,
+		Severity: severityWarning,
+		Network:  networkConfig.Name,
+		Contact:  m.chainCfg.contact(networkConfig, "", severityWarning),
+	}
+	var breached bool
+	switch {
+	case p.err != nil && !errors.Is(p.err, errProbeUnsupported):
+		breached = true
+		alert.Detail = p.err.Error()
+	case failed == 1:
+		breached = true
+		alert.Detail = "no wallet could be fetched"
+	}
+	breakerErr := breakerError(endpoint, time.Now())
+	if breakerErr != nil {
+		breached = true
+		alert.Detail = breakerErr.Error()
+	}
+	rule, err := networkConfig.breachRule(Wallet{})
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "endpoint", endpoint, "err", err)
+	}
+	rule.count, rule.duration = cmp.Or(m.chainCfg.EndpointFailures, defaultEndpointFailures), 0
+	if breakerErr != nil {
+		rule.count, rule.cooldown = 0, max(rule.cooldown, networkConfig.breakerCooldown())
+	}
+	alert, notify, err := m.evaluate(alert, breached, rule)
+	if err != nil {
+		slog.Error("Recording alert state failed", "chain", networkConfig.Name, "endpoint", endpoint, "err", err)
+	}
+	if notify {
+		m.notify(alert)
+	}
+}
+
+// order the rpc urls of a network from the best to the worst score,
+// endpoints without stats keep their place among perfect ones
+func (m *monitor) rankEndpoints(networkConfig *NetworkConfig) {
+	st, err := m.store.load()
+	if err != nil {
+		slog.Error("Loading endpoint stats failed", "chain", networkConfig.Name, "err", err)
+		return
+	}
+	score := func(endpoint string) float64 {
+		if e, ok := st.Endpoints[endpoint]; ok {
+			return e.score()
+		}
+		return 100
+	}
+	ranked := slices.Clone(networkConfig.RPCs)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score(ranked[i]) > score(ranked[j])
+	})
+	networkConfig.RPCs = ranked
+	if len(ranked) > 0 {
+		networkConfig.RPC = ranked[0]
+	}
+}
+
+type EndpointReport struct {
+	URL        string
+	Network    string
+	Score      float64
+	ErrorRate  float64
+	LatencyMs  float64
+	StalenessS float64
+	Suggestion string
+}
+
+// endpoints ordered from worst to best score
+func endpointReports(st *AlertState) []EndpointReport {
+	var reports []EndpointReport
+	for url, e := range st.Endpoints {
+		reports = append(reports, EndpointReport{
+			URL:        url,
+			Network:    e.Network,
+			Score:      math.Round(e.score()),
+			ErrorRate:  e.ErrorRate,
+			LatencyMs:  e.LatencyMs,
+			StalenessS: e.StalenessS,
+			Suggestion: e.suggestion(),
+		})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Score < reports[j].Score
+	})
+	return reports
+}
+
+// endpoints prints the health score of every rpc endpoint seen so far
+func endpoints(args []string) error {
+	fs := flag.NewFlagSet("endpoints", flag.ExitOnError)
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	st, err := newStateStore(chainCfg.statePath()).load()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Network\tEndpoint\tScore\tErrors\tLatency\tStaleness\tSuggestion")
+	for _, e := range endpointReports(st) {
+		fmt.Fprintf(w, "%s\t%s\t%.0f\t%.1f%%\t%.0fms\t%.0fs\t%s\n", e.Network, e.URL, e.Score, e.ErrorRate*100, e.LatencyMs, e.StalenessS, e.Suggestion)
+	}
+	return w.Flush()
+}
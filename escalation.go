@@ -0,0 +1,72 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// re-alert a breach that lasted After consecutive checks, at a higher
+// severity and optionally on other channels, escalating to critical wakes
+// the critical only sinks such as pushover and twilio and pages on opsgenie
+type EscalationStep struct {
+	After int `json:"after"`
+	// critical unless set
+	Severity string   `json:"severity,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+	// contact to mention in addition to the owner
+	Contact string `json:"contact,omitempty"`
+}
+
+func (n NetworkConfig) escalation(w Wallet) []EscalationStep {
+	if len(w.Escalation) > 0 {
+		return w.Escalation
+	}
+	return n.Escalation
+}
+
+func validateEscalation(steps []EscalationStep, contacts map[string]Contact) error {
+	after := 0
+	for _, s := range steps {
+		if s.After <= after {
+			return fmt.Errorf("escalation steps need an increasing number of checks, got %d after %d", s.After, after)
+		}
+		after = s.After
+		if s.Severity != "" && s.Severity != severityWarning && s.Severity != severityCritical {
+			return fmt.Errorf("unknown escalation severity %q", s.Severity)
+		}
+		if _, ok := contacts[s.Contact]; s.Contact != "" && !ok {
+			return fmt.Errorf("unknown escalation contact %q", s.Contact)
+		}
+	}
+	return nil
+}
+
+// alert as escalated by the step, severity never drops
+func (s EscalationStep) apply(alert Alert) Alert {
+	alert.Severity = s.severity(alert.Severity)
+	if len(s.Channels) > 0 {
+		alert.Channels = s.Channels
+	}
+	alert.Escalation = &s
+	return alert
+}
+
+// severity an alert escalates to, critical ones stay critical
+func (s EscalationStep) severity(severity string) string {
+	if severity == severityCritical {
+		return severity
+	}
+	return cmp.Or(s.Severity, severityCritical)
+}
+
+// mentions added by the escalation of an alert
+func (c *ChainConfig) escalationContact(alert Alert) Contact {
+	var contact Contact
+	if e := alert.Escalation; e != nil {
+		contact = c.Contacts[e.Contact]
+		if name, ok := c.SeverityContacts[alert.Severity]; ok {
+			contact = contact.merge(c.Contacts[name])
+		}
+	}
+	return contact
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// alert when the fee allowance granted to a wallet runs low or expires
+type FeegrantCheck struct {
+	// only grants of this granter count, any granter if empty
+	Granter   string `json:"granter,omitempty"`
+	Threshold string `json:"threshold"`
+	// alert once the grant expires within this duration
+	Expiry string `json:"expiry,omitempty"`
+}
+
+type FeegrantAllowances struct {
+	Allowances []struct {
+		Granter   string       `json:"granter"`
+		Grantee   string       `json:"grantee"`
+		Allowance FeeAllowance `json:"allowance"`
+	} `json:"allowances"`
+}
+
+// basic, periodic or allowed message allowance, the latter two wrap a basic
+// allowance and any allowance respectively
+type FeeAllowance struct {
+	Type           string        `json:"@type"`
+	SpendLimit     []Balances    `json:"spend_limit"`
+	Expiration     *time.Time    `json:"expiration"`
+	Basic          *FeeAllowance `json:"basic"`
+	PeriodCanSpend []Balances    `json:"period_can_spend"`
+	PeriodReset    *time.Time    `json:"period_reset"`
+	Allowance      *FeeAllowance `json:"allowance"`
+}
+
+// remaining spend limit and expiry of a grantee's allowance,
+// a nil limit means the allowance is unlimited
+type Feegrant struct {
+	Granter     string
+	Limit       *big.Int
+	Expiration  *time.Time
+	PeriodReset *time.Time
+}
+
+// amount of denom in coins, nil without a limit
+func coinLimit(coins []Balances, denom string) *big.Int {
+	if len(coins) == 0 {
+		return nil
+	}
+	limit := new(big.Int)
+	for _, c := range coins {
+		if strings.EqualFold(c.Denom, denom) {
+			limit.SetString(c.Amount, 10)
+		}
+	}
+	return limit
+}
+
+func (a *FeeAllowance) resolve(fg *Feegrant, denom string) error {
+	switch {
+	case strings.HasSuffix(a.Type, ".BasicAllowance"):
+		fg.Limit = coinLimit(a.SpendLimit, denom)
+		fg.Expiration = a.Expiration
+	case strings.HasSuffix(a.Type, ".PeriodicAllowance"):
+		if a.Basic != nil {
+			a.Basic.Type = ".BasicAllowance"
+			if err := a.Basic.resolve(fg, denom); err != nil {
+				return err
+			}
+		}
+		// what is left of this period's limit
+		if period := coinLimit(a.PeriodCanSpend, denom); period != nil && (fg.Limit == nil || period.Cmp(fg.Limit) < 0) {
+			fg.Limit = period
+		}
+		fg.PeriodReset = a.PeriodReset
+	case strings.HasSuffix(a.Type, ".AllowedMsgAllowance"):
+		if a.Allowance == nil {
+			return fmt.Errorf("allowed message allowance without allowance")
+		}
+		return a.Allowance.resolve(fg, denom)
+	default:
+		return fmt.Errorf("unsupported allowance type %s", a.Type)
+	}
+	return nil
+}
+
+func getFeegrant(ctx context.Context, lcd, grantee, granter, denom string) (*Feegrant, error) {
+	var res FeegrantAllowances
+	if err := getJSON(ctx, fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowances/%s", lcd, url.PathEscape(grantee)), &res); err != nil {
+		return nil, err
+	}
+	for _, a := range res.Allowances {
+		if granter != "" && a.Granter != granter {
+			continue
+		}
+		fg := &Feegrant{Granter: a.Granter}
+		if err := a.Allowance.resolve(fg, denom); err != nil {
+			return nil, err
+		}
+		return fg, nil
+	}
+	return nil, nil
+}
+
+// check the fee grants of a cosmos network's wallets
+func (m *monitor) checkFeegrants(ctx context.Context, out io.Writer, networkConfig NetworkConfig) {
+	if networkConfig.Type != "cosmos" {
+		return
+	}
+	for _, w := range networkConfig.Wallets {
+		if w.Feegrant == nil {
+			continue
+		}
+		if err := m.checkFeegrant(ctx, out, networkConfig, w); err != nil {
+			slog.Error("Checking feegrant failed", "chain", networkConfig.Name, "wallet", w.Name, "err", err)
+		}
+	}
+}
+
+func (m *monitor) checkFeegrant(ctx context.Context, out io.Writer, networkConfig NetworkConfig, w Wallet) error {
+	check := w.Feegrant
+	threshold, ok := new(big.Float).SetString(check.Threshold)
+	if !ok {
+		return fmt.Errorf("error parsing feegrant threshold value")
+	}
+	var expiry time.Duration
+	if check.Expiry != "" {
+		d, err := time.ParseDuration(check.Expiry)
+		if err != nil {
+			return fmt.Errorf("invalid feegrant expiry: %w", err)
+		}
+		expiry = d
+	}
+	fg, err := getFeegrant(ctx, networkConfig.RPC, w.Address, check.Granter, networkConfig.Coin)
+	if err != nil {
+		return err
+	}
+
+	alert := Alert{
+		Check:     "feegrant",
+		Severity:  severityCritical,
+		Network:   networkConfig.Name,
+		Wallet:    w.Name,
+		Address:   w.Address,
+		Threshold: m.chainCfg.Format.format(threshold),
+		Limit:     threshold,
+		Coin:      networkConfig.Coin,
+		Explorer:  networkConfig.Explorer,
+		Contact:   m.chainCfg.contact(networkConfig, w.Owner, severityCritical),
+		Channels:  w.Channels,
+	}
+	var breached bool
+	switch {
+	case fg == nil:
+		breached = true
+		alert.Balance = "0"
+		alert.Amount, alert.RawAmount = new(big.Float), new(big.Int)
+		alert.Detail = "no fee allowance granted"
+	case fg.Limit == nil:
+		alert.Balance = "unlimited"
+	default:
+		remaining := toDecimalUnit(fg.Limit, networkConfig.Decimals)
+		breached = exceedsBalanceThreshold(remaining, threshold)
+		alert.Balance = m.chainCfg.Format.format(remaining)
+		alert.Amount, alert.RawAmount = remaining, fg.Limit
+	}
+	var details []string
+	if fg != nil && fg.PeriodReset != nil {
+		details = append(details, "period resets "+fg.PeriodReset.UTC().Format(time.RFC3339))
+	}
+	if fg != nil && fg.Expiration != nil {
+		details = append(details, "expires "+fg.Expiration.UTC().Format(time.RFC3339))
+		if expiry > 0 && time.Until(*fg.Expiration) < expiry {
+			breached = true
+		}
+	}
+	if len(details) > 0 {
+		alert.Detail = strings.Join(details, ", ")
+	}
+	fmt.Fprintf(out, prettyFormat, w.Address, "feegrant "+alert.Balance, alert.Detail, alert.Threshold)
+
+	rule, err := networkConfig.breachRule(w)
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "wallet", w.Name, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, breached, rule)
+	if notify {
+		m.notify(alert)
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"strings"
+)
+
+// alert before the deposit paying for a contract's transactions runs out
+type FeeSharingCheck struct {
+	Contract  string `json:"contract"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner,omitempty"`
+	Threshold string `json:"threshold"`
+}
+
+// chain score holding the status of deployed contracts
+const iconChainScore = "cx0000000000000000000000000000000000000000"
+
+type ScoreStatus struct {
+	Disabled    string `json:"disabled"`
+	Blocked     string `json:"blocked"`
+	DepositInfo *struct {
+		AvailableDeposit string `json:"availableDeposit"`
+	} `json:"depositInfo"`
+}
+
+func getScoreStatus(ctx context.Context, rpcURL, contract string) (*ScoreStatus, error) {
+	params := map[string]any{
+		"to":       iconChainScore,
+		"dataType": "call",
+		"data": map[string]any{
+			"method": "getScoreStatus",
+			"params": map[string]string{"address": contract},
+		},
+	}
+	var status ScoreStatus
+	if err := callJSONRPC(ctx, rpcURL, "icx_call", params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// check the fee sharing deposits of an icon network's contracts
+func (m *monitor) checkFeeSharing(ctx context.Context, out io.Writer, networkConfig NetworkConfig) {
+	if networkConfig.Type != "icon" {
+		return
+	}
+	for _, check := range networkConfig.FeeSharing {
+		if err := m.checkFeeSharingContract(ctx, out, networkConfig, check); err != nil {
+			slog.Error("Checking fee sharing failed", "chain", networkConfig.Name, "contract", check.Name, "err", err)
+		}
+	}
+}
+
+func (m *monitor) checkFeeSharingContract(ctx context.Context, out io.Writer, networkConfig NetworkConfig, check FeeSharingCheck) error {
+	threshold, ok := new(big.Float).SetString(check.Threshold)
+	if !ok {
+		return fmt.Errorf("error parsing fee sharing threshold value")
+	}
+	status, err := getScoreStatus(ctx, networkConfig.RPC, check.Contract)
+	if err != nil {
+		return err
+	}
+
+	deposit := new(big.Int)
+	if status.DepositInfo != nil {
+		if _, ok := deposit.SetString(strings.TrimPrefix(status.DepositInfo.AvailableDeposit, "0x"), 16); !ok {
+			return fmt.Errorf("invalid deposit %q", status.DepositInfo.AvailableDeposit)
+		}
+	}
+	available := toDecimalUnit(deposit, networkConfig.Decimals)
+	breached := exceedsBalanceThreshold(available, threshold)
+
+	alert := Alert{
+		Check:     "fee_sharing",
+		Severity:  severityCritical,
+		Network:   networkConfig.Name,
+		Wallet:    check.Name,
+		Address:   check.Contract,
+		Balance:   m.chainCfg.Format.format(available),
+		Threshold: m.chainCfg.Format.format(threshold),
+		Amount:    available,
+		Limit:     threshold,
+		RawAmount: deposit,
+		Coin:      networkConfig.Coin,
+		Explorer:  networkConfig.Explorer,
+		Contact:   m.chainCfg.contact(networkConfig, check.Owner, severityCritical),
+	}
+	switch {
+	case status.DepositInfo == nil:
+		alert.Detail = "no fee sharing deposit, users pay their own fees"
+	case status.Disabled == "0x1":
+		breached = true
+		alert.Detail = "contract is disabled"
+	case status.Blocked == "0x1":
+		breached = true
+		alert.Detail = "contract is blocked"
+	}
+	fmt.Fprintf(out, prettyFormat, check.Contract, "deposit "+alert.Balance, alert.Detail, alert.Threshold)
+
+	// the contract has no wallet of its own, the network's rule applies
+	rule, err := networkConfig.breachRule(Wallet{})
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "contract", check.Contract, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, breached, rule)
+	if notify {
+		m.notify(alert)
+	}
+	return err
+}
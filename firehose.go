@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+var balanceWebhookURL = os.Getenv("BALANCE_WEBHOOK_URL")
+
+type BalanceChange struct {
+	Time            time.Time `json:"time"`
+	Network         string    `json:"network"`
+	Wallet          string    `json:"wallet"`
+	Address         string    `json:"address"`
+	Coin            string    `json:"coin"`
+	Decimals        uint8     `json:"decimals"`
+	Balance         string    `json:"balance"`
+	RawBalance      string    `json:"raw_balance"`
+	PreviousBalance string    `json:"previous_raw_balance,omitempty"`
+	RawDelta        string    `json:"raw_delta"`
+}
+
+// post the balance to the firehose if it changed since the last one it
+// took, a failed post is sent again on the next run
+func publishBalanceChange(store *stateStore, networkConfig NetworkConfig, r WalletBalance) error {
+	change := BalanceChange{
+		Time:       time.Now().UTC(),
+		Network:    networkConfig.Name,
+		Wallet:     r.Wallet.Name,
+		Address:    r.Wallet.Address,
+		Coin:       networkConfig.Coin,
+		Decimals:   networkConfig.Decimals,
+		Balance:    floatString(r.Balance),
+		RawBalance: r.Raw.String(),
+	}
+	key := walletKey(networkConfig.Name, r.Wallet.Address)
+	st, err := store.load()
+	if err != nil {
+		return err
+	}
+	var last string
+	if ws, ok := st.Wallets[key]; ok {
+		last = ws.LastRawBalance
+	}
+	if last == change.RawBalance {
+		return nil
+	}
+	delta := new(big.Int).Set(r.Raw)
+	if previous, ok := new(big.Int).SetString(last, 10); ok {
+		change.PreviousBalance = last
+		delta.Sub(delta, previous)
+	}
+	change.RawDelta = delta.String()
+
+	jsonMsg, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(balanceWebhookURL, "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	// only a balance the firehose took is the base of the next delta
+	return store.update(func(st *AlertState) error {
+		st.wallet(key).LastRawBalance = change.RawBalance
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+)
+
+type NumberFormat struct {
+	Locale    string `json:"locale,omitempty"`
+	Thousands string `json:"thousands,omitempty"`
+	Decimal   string `json:"decimal,omitempty"`
+	// fraction digits shown, trailing zeros are trimmed
+	Decimals *int `json:"decimals,omitempty"`
+}
+
+// thousands separator and decimal mark per locale
+var locales = map[string][2]string{
+	"en": {",", "."},
+	"ko": {",", "."},
+	"ja": {",", "."},
+	"zh": {",", "."},
+	"de": {".", ","},
+	"es": {".", ","},
+	"it": {".", ","},
+	"fr": {" ", ","},
+	"ch": {"'", "."},
+}
+
+var defaultDisplayDecimals = 4
+
+// format a balance that may be missing, empty if it is
+func (nf NumberFormat) formatOptional(f *big.Float) string {
+	if f == nil {
+		return ""
+	}
+	return nf.format(f)
+}
+
+// format an amount with grouped thousands, e.g. 1,234.567
+func (nf NumberFormat) format(f *big.Float) string {
+	marks, ok := locales[strings.ToLower(nf.Locale)]
+	if !ok {
+		marks = locales["en"]
+	}
+	if nf.Thousands != "" {
+		marks[0] = nf.Thousands
+	}
+	if nf.Decimal != "" {
+		marks[1] = nf.Decimal
+	}
+	decimals := defaultDisplayDecimals
+	if nf.Decimals != nil {
+		decimals = *nf.Decimals
+	}
+
+	s := f.Text('f', decimals)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(marks[0])
+		}
+		sb.WriteRune(d)
+	}
+	if fracPart != "" {
+		sb.WriteString(marks[1])
+		sb.WriteString(fracPart)
+	}
+	return sb.String()
+}
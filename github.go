@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	githubToken      = os.Getenv("GITHUB_TOKEN")
+	githubRepository = os.Getenv("GITHUB_REPOSITORY")
+	githubAPI        = "https://api.github.com"
+)
+
+type GitHubIssue struct {
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body,omitempty"`
+	State  string `json:"state,omitempty"`
+	Labels any    `json:"labels,omitempty"`
+}
+
+func githubRequest(method, path string, body, out any) error {
+	if githubRepository == "" {
+		return fmt.Errorf("GITHUB_REPOSITORY is not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(jsonBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, githubAPI+"/repos/"+githubRepository+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+githubToken)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, msg)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+func commentGitHubIssue(number int, body string) error {
+	return githubRequest(http.MethodPost, fmt.Sprintf("/issues/%d/comments", number), map[string]string{"body": body}, nil)
+}
+
+func breachIssueTitle(alert Alert) string {
+	return fmt.Sprintf("Low %s: %s %s", alert.subject(), alert.Network, alert.Wallet)
+}
+
+func breachLabels() []string {
+	var labels []string
+	for _, l := range strings.Split(*githubLabels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// find the open issue of an earlier breach of the wallet
+func findBreachIssue(alert Alert) (*GitHubIssue, error) {
+	query := url.Values{"state": {"open"}, "labels": {strings.Join(breachLabels(), ",")}, "per_page": {"100"}}
+	var issues []GitHubIssue
+	if err := githubRequest(http.MethodGet, "/issues?"+query.Encode(), nil, &issues); err != nil {
+		return nil, err
+	}
+	title := breachIssueTitle(alert)
+	for _, issue := range issues {
+		if issue.Title == title {
+			return &issue, nil
+		}
+	}
+	return nil, nil
+}
+
+// open an issue when a wallet breaches and close it once it recovers
+func sendGitHubIssue(chainCfg *ChainConfig, alert Alert) error {
+	issue, err := findBreachIssue(alert)
+	if err != nil {
+		return err
+	}
+	switch alert.Kind {
+	case alertBreach:
+		if issue != nil {
+			return nil
+		}
+		return githubRequest(http.MethodPost, "/issues", GitHubIssue{
+			Title:  breachIssueTitle(alert),
+			Body:   alert.message(chainCfg.language("github")),
+			Labels: breachLabels(),
+		}, nil)
+	case alertRecovery:
+		if issue == nil {
+			return nil
+		}
+		body := fmt.Sprintf("✅ %s restored to %s %s", alert.subject(), alert.Balance, alert.Coin)
+		if err := commentGitHubIssue(issue.Number, body); err != nil {
+			return err
+		}
+		return githubRequest(http.MethodPatch, fmt.Sprintf("/issues/%d", issue.Number), GitHubIssue{State: "closed"}, nil)
+	}
+	return nil
+}
+
+// markdown table of the balances checked in a run
+func runReportMarkdown(samples []Sample) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Balance check %s\n\n", time.Now().UTC().Format(time.RFC1123))
+	sb.WriteString("| Network | Wallet | Balance | Threshold | Status |\n|---|---|---|---|---|\n")
+	for _, s := range samples {
+		status := "✅"
+		if s.Breached {
+			status = "🚨"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s %s | %s %s | %s |\n", s.Network, s.Wallet, s.Balance, s.Coin, s.Threshold, s.Coin, status)
+	}
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+var googleChatWebhookURL = os.Getenv("GOOGLE_CHAT_WEBHOOK_URL")
+
+type GoogleChatMessage struct {
+	Text    string           `json:"text"`
+	CardsV2 []GoogleChatCard `json:"cardsV2"`
+}
+
+type GoogleChatCard struct {
+	CardID string         `json:"cardId"`
+	Card   map[string]any `json:"card"`
+}
+
+// card of an alert with a decorated text widget per fact
+func googleChatCard(alert Alert, lang string) map[string]any {
+	heading, facts := alert.facts(lang)
+	var widgets []map[string]any
+	for _, f := range facts {
+		widgets = append(widgets, map[string]any{
+			"decoratedText": map[string]any{"topLabel": f.Title, "text": f.Value, "wrapText": true},
+		})
+	}
+	if u := alert.explorerURL(); alert.Kind != alertBudget && u != "" {
+		widgets = append(widgets, map[string]any{
+			"buttonList": map[string]any{"buttons": []map[string]any{
+				{"text": tr(lang, "address"), "onClick": map[string]any{"openLink": map[string]string{"url": u}}},
+			}},
+		})
+	}
+	return map[string]any{
+		"header":   map[string]string{"title": heading, "subtitle": alert.Wallet},
+		"sections": []map[string]any{{"widgets": widgets}},
+	}
+}
+
+func sendGoogleChatAlert(webhookURL string, alert Alert, lang string) error {
+	heading, _ := alert.facts(lang)
+	msg := GoogleChatMessage{
+		// shown in notifications, cards are not
+		Text:    alert.emoji() + " " + heading,
+		CardsV2: []GoogleChatCard{{CardID: "alert", Card: googleChatCard(alert, lang)}},
+	}
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(webhookURL, "application/json; charset=UTF-8", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	grafanaURL          = os.Getenv("GRAFANA_URL")
+	grafanaAPIKey       = os.Getenv("GRAFANA_API_KEY")
+	grafanaDashboardUID = os.Getenv("GRAFANA_DASHBOARD_UID")
+)
+
+type GrafanaAnnotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// annotate when a wallet starts breaching or recovers
+func sendGrafanaAnnotation(alert Alert) error {
+	var event, text string
+	switch {
+	case alert.Kind == alertRecovery:
+		event = "recovery"
+		text = fmt.Sprintf("%s %s %s recovered: %s %s", alert.Network, alert.Wallet, alert.subject(), alert.Balance, alert.Coin)
+	case alert.New:
+		event = "breach"
+		text = fmt.Sprintf("%s %s %s below threshold: %s %s < %s %s", alert.Network, alert.Wallet, alert.subject(), alert.Balance, alert.Coin, alert.Threshold, alert.Coin)
+	default:
+		return nil
+	}
+
+	annotation := GrafanaAnnotation{
+		DashboardUID: grafanaDashboardUID,
+		Time:         time.Now().UnixMilli(),
+		Tags:         []string{"balance-tracker", event, "chain:" + alert.Network, "wallet:" + alert.Wallet},
+		Text:         text,
+	}
+	jsonMsg, err := json.Marshal(annotation)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(grafanaURL, "/")+"/api/annotations", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+grafanaAPIKey)
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+// cleartext http/2 for grpc:// and http:// endpoints, such as a node on the
+// private network, net/http only speaks http/2 over tls
+var h2cClient = &http.Client{Transport: &http2.Transport{
+	AllowHTTP: true,
+	DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	},
+}}
+
+// append a length delimited protobuf field
+func protoString(buf []byte, field int, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|2))
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// length delimited fields of a protobuf message by field number,
+// other wire types are skipped
+func protoFields(msg []byte) (map[int][]byte, error) {
+	fields := make(map[int][]byte)
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, errors.New("invalid protobuf tag")
+		}
+		msg = msg[n:]
+		switch tag & 7 {
+		case 0:
+			_, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return nil, errors.New("invalid protobuf varint")
+			}
+			msg = msg[n:]
+		case 1:
+			msg = msg[min(8, len(msg)):]
+		case 2:
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < l {
+				return nil, errors.New("invalid protobuf length")
+			}
+			fields[int(tag>>3)] = msg[n : n+int(l)]
+			msg = msg[n+int(l):]
+		case 5:
+			msg = msg[min(4, len(msg)):]
+		default:
+			return nil, fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+	}
+	return fields, nil
+}
+
+// unary grpc call over http/2, tls for https:// endpoints and plaintext
+// for grpc:// and http:// ones
+func callGRPC(ctx context.Context, endpoint, method string, req []byte) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client := http.DefaultClient
+	switch u.Scheme {
+	case "https":
+	case "grpc", "http":
+		u.Scheme, client = "http", h2cClient
+	default:
+		return nil, fmt.Errorf("grpc endpoint must be https, grpc or http: %s", endpoint)
+	}
+
+	frame := make([]byte, 5, 5+len(req))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(req)))
+	frame = append(frame, req...)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(u.String(), "/")+method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("TE", "trailers")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// errors without a message come as headers only
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		msg, _ := url.PathUnescape(message)
+		return nil, fmt.Errorf("grpc error %s: %s", status, msg)
+	}
+	if len(body) < 5 {
+		return nil, errors.New("empty grpc response")
+	}
+	if body[0] != 0 {
+		return nil, errors.New("compressed grpc responses are not supported")
+	}
+	size := binary.BigEndian.Uint32(body[1:5])
+	if uint64(len(body)-5) < uint64(size) {
+		return nil, errors.New("truncated grpc response")
+	}
+	return body[5 : 5+size], nil
+}
+
+const bankBalanceMethod = "/cosmos.bank.v1beta1.Query/Balance"
+
+// QueryBalanceRequest{address, denom}
+func bankBalanceRequest(address, denom string) []byte {
+	var req []byte
+	req = protoString(req, 1, address)
+	return protoString(req, 2, denom)
+}
+
+// QueryBalanceResponse{balance: Coin{denom, amount}}
+func parseBankBalance(res []byte) (*big.Int, error) {
+	fields, err := protoFields(res)
+	if err != nil {
+		return nil, err
+	}
+	coin, err := protoFields(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(coin[2]) == 0 {
+		return new(big.Int), nil
+	}
+	balance, ok := new(big.Int).SetString(string(coin[2]), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %q", coin[2])
+	}
+	return balance, nil
+}
+
+func getCosmosBalanceGRPC(ctx context.Context, endpoint, address, denom string) (*big.Int, error) {
+	res, err := callGRPC(ctx, endpoint, bankBalanceMethod, bankBalanceRequest(address, denom))
+	if err != nil {
+		return nil, err
+	}
+	return parseBankBalance(res)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// completed checks of the daemon, read by the kubernetes probes
+type healthStatus struct {
+	mu sync.Mutex
+	// end of the last completed check
+	lastCheck time.Time
+	// end of the last check that recorded a sample
+	lastSuccess time.Time
+}
+
+func (h *healthStatus) record(success bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastCheck = time.Now().UTC()
+	if success {
+		h.lastSuccess = h.lastCheck
+	}
+}
+
+type HealthResponse struct {
+	Status      string     `json:"status"`
+	LastCheck   *time.Time `json:"last_check,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+func (h *healthStatus) response(status string) HealthResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	res := HealthResponse{Status: status}
+	if lastCheck := h.lastCheck; !lastCheck.IsZero() {
+		res.LastCheck = &lastCheck
+	}
+	if lastSuccess := h.lastSuccess; !lastSuccess.IsZero() {
+		res.LastSuccess = &lastSuccess
+	}
+	return res
+}
+
+// alive while the daemon serves requests
+func (h *healthStatus) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeStatusJSON(w, http.StatusOK, h.response("ok"))
+}
+
+// ready once the config is loaded and a check recorded a sample
+func (h *healthStatus) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	res := h.response("ready")
+	if res.LastSuccess == nil {
+		res.Status = "waiting for the first successful check"
+		writeStatusJSON(w, http.StatusServiceUnavailable, res)
+		return
+	}
+	writeStatusJSON(w, http.StatusOK, res)
+}
+
+func writeStatusJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Writing response failed", "err", err)
+	}
+}
+
+// serve the probes and metrics of daemon mode until ctx is cancelled
+func serveHealth(ctx context.Context, m *monitor) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", m.health.handleHealthz)
+	mux.HandleFunc("GET /readyz", m.health.handleReadyz)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(daemonRegistry(), promhttp.HandlerOpts{}))
+	listenUntil(ctx, *listen, mux)
+}
+
+// serve the profiles of daemon mode on their own address until ctx is
+// cancelled, they expose internals and must not share the public listener
+func serveProfiles(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	listenUntil(ctx, *pprofListen, mux)
+}
+
+func listenUntil(ctx context.Context, addr string, handler http.Handler) {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("Listening", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Serving http failed", "address", addr, "err", err)
+	}
+}
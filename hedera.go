@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+)
+
+type HederaAccount struct {
+	Balance struct {
+		Balance int64 `json:"balance"`
+	} `json:"balance"`
+}
+
+// balance in tinybars from a mirror node, accounts are 0.0.x ids or evm addresses
+func getHederaBalance(ctx context.Context, mirrorNode, account string) (*big.Int, error) {
+	var res HederaAccount
+	if err := getJSON(ctx, fmt.Sprintf("%s/api/v1/accounts/%s", mirrorNode, url.PathEscape(account)), &res); err != nil {
+		return nil, err
+	}
+	return big.NewInt(res.Balance.Balance), nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+)
+
+// one observed balance of a wallet
+type Sample struct {
+	Time       time.Time `json:"time"`
+	Network    string    `json:"network"`
+	Wallet     string    `json:"wallet"`
+	Address    string    `json:"address"`
+	Coin       string    `json:"coin"`
+	Balance    string    `json:"balance"`
+	Threshold  string    `json:"threshold"`
+	Breached   bool      `json:"breached"`
+	Suppressed bool      `json:"suppressed,omitempty"`
+	// rpc url the balance was fetched from
+	Endpoint string `json:"endpoint,omitempty"`
+	Staked   string `json:"staked,omitempty"`
+	Rewards  string `json:"rewards,omitempty"`
+}
+
+func (s Sample) staked() float64 {
+	f, _ := strconv.ParseFloat(s.Staked, 64)
+	return f
+}
+
+func (s Sample) rewards() float64 {
+	f, _ := strconv.ParseFloat(s.Rewards, 64)
+	return f
+}
+
+// plain decimal of an optional balance, full precision and no exponent
+func floatString(f *big.Float) string {
+	if f == nil {
+		return ""
+	}
+	return f.Text('f', -1)
+}
+
+func (s Sample) key() string {
+	return walletKey(s.Network, s.Address)
+}
+
+func (s Sample) balance() float64 {
+	f, _ := strconv.ParseFloat(s.Balance, 64)
+	return f
+}
+
+func (s Sample) threshold() float64 {
+	f, _ := strconv.ParseFloat(s.Threshold, 64)
+	return f
+}
+
+// append samples to the history file, one json object per line
+func appendHistory(path string, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, s := range samples {
+		if err := enc.Encode(s); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// read samples recorded at or after since
+func readHistory(path string, since time.Time) ([]Sample, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, err
+		}
+		if s.Time.Before(since) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
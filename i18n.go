@@ -0,0 +1,114 @@
+package main
+
+// message catalog of alert and report texts, english is the fallback
+var catalog = map[string]map[string]string{
+	"en": {
+		"alert":             "Alert",
+		"warning_alert":     "Low Balance Warning",
+		"empty_alert":       "Wallet Empty",
+		"restored":          "Balance restored",
+		"alerts":            "alerts",
+		"suppressed":        "further alerts suppressed",
+		"critical":          "critical",
+		"warning":           "warning",
+		"recovered":         "recovered",
+		"resolved":          "resolved",
+		"budget_alert":      "Budget Alert",
+		"feegrant_alert":    "Feegrant Alert",
+		"allowance_alert":   "Allowance Alert",
+		"fee_sharing_alert": "Fee Sharing Alert",
+		"token_alert":       "Token Alert",
+		"drain_alert":       "Drain Alert",
+		"endpoint_alert":    "RPC Endpoint Failing",
+		"remaining":         "Remaining",
+		"staked":            "Staked",
+		"rewards":           "Claimable rewards",
+		"claim_first":       "consider claiming before topping up",
+		"wallet":            "Wallet",
+		"address":           "Address",
+		"chain":             "Chain",
+		"endpoint":          "Endpoint",
+		"balance":           "Balance",
+		"threshold":         "Threshold",
+		"last_tx":           "Last tx",
+		"escalated":         "Escalated after",
+		"checks":            "checks below threshold",
+		"scope":             "Scope",
+		"all_wallets":       "all wallets",
+		"spent_month":       "Spent this month",
+		"projected":         "Projected",
+		"budget":            "Budget",
+		"acknowledge":       "Acknowledge",
+		"snooze":            "Snooze 4h",
+		"funding_report":    "Funding report",
+		"breaches":          "Breaches",
+		"spent":             "Spent",
+		"topped_up":         "Topped up",
+		"top_ups":           "Top-ups",
+		"runway":            "Runway",
+		"sla_7d":            "SLA 7d",
+		"sla_30d":           "SLA 30d",
+		"monthly_budget":    "Monthly budgets",
+	},
+	"ko": {
+		"alert":             "알림",
+		"warning_alert":     "잔액 부족 경고",
+		"empty_alert":       "지갑 잔액 없음",
+		"restored":          "잔액 회복",
+		"alerts":            "건의 알림",
+		"suppressed":        "건의 추가 알림이 억제됨",
+		"critical":          "심각",
+		"warning":           "경고",
+		"recovered":         "회복",
+		"resolved":          "해결됨",
+		"budget_alert":      "예산 알림",
+		"feegrant_alert":    "수수료 위임 알림",
+		"allowance_alert":   "승인 한도 알림",
+		"fee_sharing_alert": "수수료 대납 알림",
+		"token_alert":       "토큰 알림",
+		"drain_alert":       "잔액 급감 알림",
+		"endpoint_alert":    "RPC 엔드포인트 장애",
+		"remaining":         "잔여",
+		"staked":            "스테이킹",
+		"rewards":           "청구 가능한 보상",
+		"claim_first":       "충전 전에 보상 청구를 고려하세요",
+		"wallet":            "지갑",
+		"address":           "주소",
+		"chain":             "체인",
+		"endpoint":          "엔드포인트",
+		"balance":           "잔액",
+		"threshold":         "임계값",
+		"last_tx":           "최근 거래",
+		"escalated":         "에스컬레이션",
+		"checks":            "회 연속 임계값 미만",
+		"scope":             "범위",
+		"all_wallets":       "전체 지갑",
+		"spent_month":       "이번 달 지출",
+		"projected":         "예상 지출",
+		"budget":            "예산",
+		"acknowledge":       "확인",
+		"snooze":            "4시간 보류",
+		"funding_report":    "자금 보고서",
+		"breaches":          "임계값 미달",
+		"spent":             "지출",
+		"topped_up":         "충전",
+		"top_ups":           "충전 횟수",
+		"runway":            "잔여 기간",
+		"sla_7d":            "SLA 7일",
+		"sla_30d":           "SLA 30일",
+		"monthly_budget":    "월간 예산",
+	},
+}
+
+// translate a catalog key
+func tr(lang, key string) string {
+	if msg, ok := catalog[lang][key]; ok {
+		return msg
+	}
+	return catalog["en"][key]
+}
+
+// language configured for a channel
+func (c *ChainConfig) language(channel string) string {
+	return c.Languages[channel]
+}
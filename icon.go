@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type ICONNetworkInfo struct {
+	Platform string `json:"platform"`
+	NID      string `json:"nid"`
+}
+
+// make sure the rpc of an icon or goloop fork network serves the expected chain
+func checkICONNetworkID(ctx context.Context, rpcURL, nid string) error {
+	var info ICONNetworkInfo
+	if err := callJSONRPC(ctx, rpcURL, "icx_getNetworkInfo", nil, &info); err != nil {
+		return fmt.Errorf("error getting network info: %w", err)
+	}
+	if !strings.EqualFold(info.NID, nid) {
+		return fmt.Errorf("network id mismatch: expected %s, rpc serves %s (%s)", nid, info.NID, info.Platform)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type JSONRPCRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type JSONRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+type JSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// call a json-rpc 2.0 method over http
+func callJSONRPC(ctx context.Context, endpoint, method string, params, result any) error {
+	jsonReq, err := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonReq))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s: %w", method, &HTTPStatusError{StatusCode: resp.StatusCode})
+		}
+		return fmt.Errorf("%s: unexpected response: %w", method, err)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Result, result)
+}
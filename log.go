@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+var (
+	// minimum level of the logs, set by the -log-level flag of every command
+	logLevel slog.LevelVar
+	// text for humans or json, one object per event, for log aggregation
+	logFormat = "text"
+)
+
+func logFlags(fs *flag.FlagSet) {
+	fs.Func("log-level", "minimum level of the logs: debug, info, warn or error (default info)", func(level string) error {
+		return logLevel.UnmarshalText([]byte(level))
+	})
+	fs.Func("log-format", "format of the logs: text or json (default text)", func(format string) error {
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown log format %q", format)
+		}
+		logFormat = format
+		return nil
+	})
+}
+
+// writes each record in the format of the -log-format flag, which is only
+// parsed after the logger is set up
+type formatHandler struct {
+	text slog.Handler
+	json slog.Handler
+}
+
+func (h formatHandler) handler() slog.Handler {
+	if logFormat == "json" {
+		return h.json
+	}
+	return h.text
+}
+
+func (h formatHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h formatHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return formatHandler{text: h.text.WithAttrs(attrs), json: h.json.WithAttrs(attrs)}
+}
+
+func (h formatHandler) WithGroup(name string) slog.Handler {
+	return formatHandler{text: h.text.WithGroup(name), json: h.json.WithGroup(name)}
+}
+
+// logs go to stderr, leaving stdout to the balance tables and reports
+func setupLogging() {
+	opts := &slog.HandlerOptions{Level: &logLevel}
+	slog.SetDefault(slog.New(formatHandler{
+		text: slog.NewTextHandler(os.Stderr, opts),
+		json: slog.NewJSONHandler(os.Stderr, opts),
+	}))
+}
+
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
+}
@@ -2,52 +2,188 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math/big"
+	"math/rand/v2"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
-
-	iconclient "github.com/icon-project/goloop/client"
-	"github.com/icon-project/goloop/server/jsonrpc"
-	v3 "github.com/icon-project/goloop/server/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
 	timeout           = 10 * time.Second
 	filePath          = "./wallets.json"
+	stateFile         = "./state.json"
+	historyFile       = "./history.jsonl"
 	telegramBotToken  = os.Getenv("TELEGRAM_BOT_TOKEN")
+	telegramChatID    = os.Getenv("TELEGRAM_CHAT_ID")
 	discordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
 	prettyFormat      = "%-50s %-35s %-25s %-20s\n"
+	// client of every alert sink, a hung endpoint must not hold up the
+	// deliveries and the shutdown waiting for them
+	sinkClient = &http.Client{Timeout: timeout}
+	// retries of a failed alert delivery, the backoff doubles after each
+	alertRetries = 3
+	alertBackoff = time.Second
+)
+
+// chains with fixed native decimals do not need them configured
+var nativeDecimals = map[string]uint8{
+	"solana":   9,
+	"sui":      9,
+	"stellar":  7,
+	"aptos":    8,
+	"tron":     6,
+	"utxo":     8,
+	"near":     24,
+	"algorand": 6,
+	"xrpl":     6,
+	"starknet": 18,
+	"ton":      9,
+	"hedera":   8,
+	"cardano":  6,
+}
+
+var (
+	githubIssue        = flag.Int("github-issue", 0, "comment each run's report on this GitHub issue")
+	githubBreachIssues = flag.Bool("github-breach-issues", false, "open a GitHub issue per breach and close it on recovery")
+	githubLabels       = flag.String("github-labels", "balance-alert", "comma separated labels for breach issues")
+	promTextfile       = flag.String("prom-textfile", "", "write prometheus gauges to this node_exporter textfile")
+	pushgateway        = flag.String("pushgateway", "", "push each run's gauges to this prometheus pushgateway url, e.g. from cron")
+	pushgatewayJob     = flag.String("pushgateway-job", "balance_tracker", "job label of the metrics pushed to the pushgateway")
+	emailSummary       = flag.Bool("email-summary", false, "email an html table of the checked balances, e.g. from a daily cron job")
+	daemon             = flag.Bool("daemon", false, "keep running and check the balances every interval instead of once")
+	interval           = flag.Duration("interval", 5*time.Minute, "time between checks in daemon mode")
+	listen             = flag.String("listen", ":8080", "address serving /healthz, /readyz and /metrics in daemon mode, empty to disable")
+	profiling          = flag.Bool("pprof", false, "serve net/http/pprof under /debug/pprof/ on the -pprof-listen address in daemon mode")
+	watchBlocks        = flag.Bool("watch", false, "in daemon mode also re-check wallets as soon as new blocks on the networks' ws endpoints touch them")
+	pprofListen        = flag.String("pprof-listen", "localhost:6060", "address serving the profiles of -pprof, kept apart from the public -listen address")
 )
 
 type Wallet struct {
-	Address string `json:"address"`
-	Name    string `json:"name"`
-	Alert   bool   `json:"alert"`
+	Address             string           `json:"address"`
+	Name                string           `json:"name"`
+	Alert               bool             `json:"alert"`
+	WarningThreshold    string           `json:"warning_threshold,omitempty"`
+	CriticalThreshold   string           `json:"critical_threshold,omitempty"`
+	For                 string           `json:"for,omitempty"`
+	ConsecutiveBreaches int              `json:"consecutive_breaches,omitempty"`
+	Cooldown            string           `json:"cooldown,omitempty"`
+	DrainHorizon        string           `json:"drain_horizon,omitempty"`
+	Dust                string           `json:"dust,omitempty"`
+	Escalation          []EscalationStep `json:"escalation,omitempty"`
+	Interval            string           `json:"interval,omitempty"`
+	Owner               string           `json:"owner,omitempty"`
+	MonthlyBudget       string           `json:"monthly_budget,omitempty"`
+	Feegrant            *FeegrantCheck   `json:"feegrant,omitempty"`
+	Allowances          []AllowanceCheck `json:"allowances,omitempty"`
+	Tokens              []TokenConfig    `json:"tokens,omitempty"`
+	// named channels the wallet alerts instead of the default ones
+	Channels []string `json:"channels,omitempty"`
+	// "contract" for evm contracts such as a safe, reported with their owners and token holdings
+	Kind string `json:"kind,omitempty"`
 }
 
 type NetworkConfig struct {
-	Type      string   `json:"type"`
-	RPC       string   `json:"rpc"`
-	Explorer  string   `json:"explorer"`
-	Coin      string   `json:"coin"`
-	Name      string   `json:"name"`
-	Decimals  uint8    `json:"decimals"`
-	Threshold string   `json:"threshold"`
-	Wallets   []Wallet `json:"wallets"`
+	Type string  `json:"type"`
+	RPCs rpcList `json:"rpc"`
+	// endpoint in use, the first of the list unless failing over
+	RPC           string `json:"-"`
+	PreferBestRPC bool   `json:"prefer_best_rpc,omitempty"`
+	VerifyRPC     string `json:"verify_rpc,omitempty"`
+	WS            string `json:"ws,omitempty"`
+	GRPC          string `json:"grpc,omitempty"`
+	TendermintRPC string `json:"tendermint_rpc,omitempty"`
+	NetworkID     string `json:"network_id,omitempty"`
+	FeeToken      string `json:"fee_token,omitempty"`
+	RPCKeyEnv     string `json:"rpc_key_env,omitempty"`
+	// api the rpc speaks where a chain has several, e.g. blockfrost or koios
+	Provider string `json:"provider,omitempty"`
+	// track staked balances, the threshold applies to the liquid or total balance
+	Staking      string `json:"staking,omitempty"`
+	Rewards      bool   `json:"rewards,omitempty"`
+	Explorer     string `json:"explorer"`
+	TxExplorer   string `json:"tx_explorer,omitempty"`
+	TxAPI        string `json:"tx_api,omitempty"`
+	TxAPIKeyEnv  string `json:"tx_api_key_env,omitempty"`
+	Coin         string `json:"coin"`
+	Denom        string `json:"denom,omitempty"`
+	Bech32Prefix string `json:"bech32_prefix,omitempty"`
+	Name         string `json:"name"`
+	Decimals     uint8  `json:"decimals"`
+	Threshold    string `json:"threshold"`
+	// alias of threshold, wallet thresholds override the network ones
+	CriticalThreshold   string            `json:"critical_threshold,omitempty"`
+	WarningThreshold    string            `json:"warning_threshold,omitempty"`
+	For                 string            `json:"for,omitempty"`
+	ConsecutiveBreaches int               `json:"consecutive_breaches,omitempty"`
+	Cooldown            string            `json:"cooldown,omitempty"`
+	DrainHorizon        string            `json:"drain_horizon,omitempty"`
+	Dust                string            `json:"dust,omitempty"`
+	Escalation          []EscalationStep  `json:"escalation,omitempty"`
+	Interval            string            `json:"interval,omitempty"`
+	Schedule            string            `json:"schedule,omitempty"`
+	MaxConcurrency      int               `json:"max_concurrency,omitempty"`
+	Timeout             string            `json:"timeout,omitempty"`
+	CallTimeout         string            `json:"call_timeout,omitempty"`
+	MaxAttempts         int               `json:"max_attempts,omitempty"`
+	BreakerFailures     int               `json:"breaker_failures,omitempty"`
+	BreakerCooldown     string            `json:"breaker_cooldown,omitempty"`
+	Owner               string            `json:"owner,omitempty"`
+	MonthlyBudget       string            `json:"monthly_budget,omitempty"`
+	Wallets             []Wallet          `json:"wallets"`
+	FeeSharing          []FeeSharingCheck `json:"fee_sharing,omitempty"`
+	Tokens              []TokenConfig     `json:"tokens,omitempty"`
 }
 
 type ChainConfig struct {
-	Chains []NetworkConfig `json:"info"`
+	Profile      string             `json:"profile,omitempty"`
+	StateFile    string             `json:"state_file,omitempty"`
+	HistoryFile  string             `json:"history_file,omitempty"`
+	Chains       []NetworkConfig    `json:"info"`
+	Contacts     map[string]Contact `json:"contacts,omitempty"`
+	Format       NumberFormat       `json:"number_format,omitempty"`
+	Languages    map[string]string  `json:"languages,omitempty"`
+	Suppressions []Suppression      `json:"suppressions,omitempty"`
+	// urls receiving every alert as a json payload
+	Webhooks []string                 `json:"webhooks,omitempty"`
+	Matrix   *MatrixConfig            `json:"matrix,omitempty"`
+	Channels map[string]ChannelConfig `json:"channels,omitempty"`
+	// text/template alert messages keyed by channel, channel type or "default"
+	Templates map[string]string `json:"templates,omitempty"`
+	// group the alerts of a run into one message per channel
+	Digest bool `json:"digest,omitempty"`
+	// contact mentioned on every alert of a severity, e.g. {"critical": "oncall"}
+	SeverityContacts map[string]string `json:"severity_contacts,omitempty"`
+	RateLimit        *RateLimit        `json:"rate_limit,omitempty"`
+	EndpointFailures int               `json:"endpoint_failures,omitempty"`
+	templates        map[string]*template.Template
+}
+
+// who to mention on each channel when an owner's wallet alerts
+type Contact struct {
+	DiscordRoles []string `json:"discord_roles,omitempty"`
+	DiscordUsers []string `json:"discord_users,omitempty"`
+	Telegram     []string `json:"telegram,omitempty"`
+	Slack        []string `json:"slack,omitempty"`
 }
 
 type Balances struct {
@@ -65,124 +201,568 @@ type TelegramMessage struct {
 }
 
 type DiscordMessage struct {
-	Content string `json:"content"`
+	Content string         `json:"content,omitempty"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+type WalletBalance struct {
+	Wallet  Wallet
+	Raw     *big.Int
+	Balance *big.Float
+	Err     error
+	// rpc url that served the balance, or the last one that failed it
+	Endpoint string
+	// delegated balance of networks tracking staking
+	Staked *big.Float
+	// claimable staking rewards
+	Rewards *big.Float
+}
+
+// a single rpc url or a list of them tried in order
+type rpcList []string
+
+func (l *rpcList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = rpcList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
 }
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	setupLogging()
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		var err error
+		switch os.Args[1] {
+		case "serve":
+			err = serve(os.Args[2:])
+		case "report":
+			err = report(os.Args[2:])
+		case "dashboard":
+			err = dashboard(os.Args[2:])
+		case "compare":
+			err = compare(os.Args[2:])
+		case "sla":
+			err = sla(os.Args[2:])
+		case "watch":
+			err = watch(os.Args[2:])
+		case "wallet":
+			err = wallet(os.Args[2:])
+		case "endpoints":
+			err = endpoints(os.Args[2:])
+		case "snooze":
+			err = snooze(os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command: %s", os.Args[1])
+		}
+		if err != nil {
+			fatal(err)
+		}
+		return
+	}
+	configFlag(flag.CommandLine)
+	flag.Parse()
+	if *profiling && (!*daemon || *pprofListen == "") {
+		fatal(fmt.Errorf("-pprof needs -daemon and a -pprof-listen address"))
+	}
+	if *watchBlocks && !*daemon {
+		fatal(fmt.Errorf("-watch needs -daemon, use the watch command to only watch"))
+	}
+	if *interval < minInterval {
+		fatal(fmt.Errorf("-interval must be at least %s, got %s", minInterval, *interval))
+	}
 
-	content, err := os.ReadFile(filePath)
+	chainCfg, err := loadConfig(filePath)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+	shutdownTracing := setupTracing()
+	defer shutdownTracing()
+	// a signal cancels the run, alerts already raised are still delivered
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m := newMonitor(chainCfg)
+	if *daemon {
+		runDaemon(ctx, m)
+		return
 	}
+	check(ctx, m, chainCfg.Chains)
+}
 
-	var chainCfg ChainConfig
-	err = json.Unmarshal(content, &chainCfg)
-	if err != nil {
-		log.Fatal(err)
+// check the balances of the networks once and record the results, the
+// daemon's schedules check concurrently and take turns recording
+func check(ctx context.Context, m *monitor, networks []NetworkConfig) {
+	defer deliveries.Wait()
+	chainCfg := m.chainCfg
+
+	start := time.Now()
+	slog.Info("Scan started", "chains", len(networks))
+	ctx, span := tracer.Start(ctx, "run", trace.WithAttributes(attribute.Int("networks", len(networks))))
+	samples := m.run(ctx, networks)
+	span.SetAttributes(attribute.Int("samples", len(samples)))
+	span.End()
+	duration := time.Since(start)
+	slog.Info("Scan finished", "samples", len(samples), "duration", duration.Round(time.Millisecond))
+	m.health.record(len(samples) > 0)
+	latest := m.record(samples)
+	m.mu.Lock()
+	if err := checkBudgets(chainCfg, m.store); err != nil {
+		slog.Error("Checking budgets failed", "err", err)
+	}
+	m.mu.Unlock()
+	if *promTextfile != "" || *pushgateway != "" || *daemon && *listen != "" {
+		if err := recordSLA(chainCfg.historyPath(), latest); err != nil {
+			slog.Error("Computing sla failed", "err", err)
+		}
 	}
+	if *promTextfile != "" {
+		if err := writePromTextfile(*promTextfile, latest); err != nil {
+			slog.Error("Writing prometheus textfile failed", "err", err)
+		}
+	}
+	if *pushgateway != "" {
+		if err := pushMetrics(*pushgateway, latest, duration); err != nil {
+			slog.Error("Pushing metrics failed", "err", err)
+		}
+	}
+	if *emailSummary {
+		if err := sendEmailSummary(samples); err != nil {
+			slog.Error("Sending summary email failed", "err", err)
+		}
+	}
+	if *githubIssue > 0 {
+		if err := commentGitHubIssue(*githubIssue, runReportMarkdown(samples)); err != nil {
+			slog.Error("Commenting on GitHub issue failed", "err", err)
+		}
+	}
+}
 
-	for _, networkConfig := range chainCfg.Chains {
+// breach rule of a wallet, wallet settings override the network ones
+func (n NetworkConfig) breachRule(w Wallet) (breachRule, error) {
+	rule := breachRule{count: n.ConsecutiveBreaches, escalation: n.escalation(w)}
+	if w.ConsecutiveBreaches > 0 {
+		rule.count = w.ConsecutiveBreaches
+	}
+	duration := n.For
+	if w.For != "" {
+		duration = w.For
+	}
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return rule, fmt.Errorf("invalid for duration of %s: %w", w.Name, err)
+		}
+		rule.duration = d
+	}
+	cooldown := n.Cooldown
+	if w.Cooldown != "" {
+		cooldown = w.Cooldown
+	}
+	if cooldown != "" {
+		d, err := time.ParseDuration(cooldown)
+		if err != nil {
+			return rule, fmt.Errorf("invalid cooldown of %s: %w", w.Name, err)
+		}
+		rule.cooldown = d
+	}
+	return rule, nil
+}
 
-		fmt.Printf("Network: %s\n", networkConfig.Name)
+// rpc urls of a network in the order they are tried
+func (n NetworkConfig) endpoints() rpcList {
+	if len(n.RPCs) == 0 {
+		return rpcList{n.RPC}
+	}
+	return n.RPCs
+}
 
-		coinName := networkConfig.Coin
-		fmt.Printf(prettyFormat, "Address", fmt.Sprintf("Balance (%s)", coinName), "Balance", "Threshold")
-		fmt.Println(strings.Repeat("-", 125))
-		threshold, ok := new(big.Float).SetString(networkConfig.Threshold)
-		if !ok {
-			fmt.Println("Error parsing threshold value")
-			continue
-		}
-		switch networkConfig.Type {
-		case "evm":
-			client, err := rpc.DialContext(ctx, networkConfig.RPC)
-			if err != nil {
-				continue
-			}
-			defer client.Close()
+// exact denom of a cosmos coin, the coin lowercased unless configured,
+// the hash of ibc denoms is upper case
+func (n NetworkConfig) denom() string {
+	if n.Denom != "" {
+		return n.Denom
+	}
+	if isIBCDenom(n.Coin) {
+		return "ibc/" + strings.ToUpper(n.Coin[len("ibc/"):])
+	}
+	return strings.ToLower(n.Coin)
+}
 
-			for _, wallet := range networkConfig.Wallets {
-				if !wallet.Alert {
-					continue
-				}
-				balance, err := getETHBalance(client, wallet.Address)
-				if err != nil {
-					fmt.Println(err)
-					continue
-				}
+func configFlag(fs *flag.FlagSet) {
+	fs.StringVar(&filePath, "config", filePath, "path to the wallets config")
+	logFlags(fs)
+}
 
-				etherBalance := toDecimalUnit(balance, networkConfig.Decimals)
-				fmt.Printf(prettyFormat, wallet.Address, etherBalance.String(), balance.String(), threshold.String())
-				if exceedsBalanceThreshold(etherBalance, threshold) {
-					sendAlert(networkConfig.Name, wallet.Name, wallet.Address, etherBalance.String(), threshold.String(), coinName, networkConfig.Explorer)
-				}
-			}
+// files of a profile default to the ones in the working directory
+func (c *ChainConfig) statePath() string {
+	if c.StateFile != "" {
+		return c.StateFile
+	}
+	return stateFile
+}
 
-		case "icon":
-			client := iconclient.NewClientV3(networkConfig.RPC)
-			defer client.Cleanup()
+func (c *ChainConfig) historyPath() string {
+	if c.HistoryFile != "" {
+		return c.HistoryFile
+	}
+	return historyFile
+}
 
-			for _, wallet := range networkConfig.Wallets {
-				if !wallet.Alert {
+func loadConfig(path string) (*ChainConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainCfg ChainConfig
+	if err := json.Unmarshal(content, &chainCfg); err != nil {
+		return nil, err
+	}
+	for i, networkConfig := range chainCfg.Chains {
+		if len(networkConfig.RPCs) > 0 {
+			chainCfg.Chains[i].RPC = networkConfig.RPCs[0]
+		}
+		if networkConfig.Decimals == 0 {
+			chainCfg.Chains[i].Decimals = nativeDecimals[networkConfig.Type]
+		}
+		// evm addresses of ethermint chains are converted to their bech32 form
+		if networkConfig.Type == "cosmos" && networkConfig.Bech32Prefix != "" {
+			for j, w := range networkConfig.Wallets {
+				if !strings.HasPrefix(w.Address, "0x") {
 					continue
 				}
-				balance, err := getICXBalance(client, wallet.Address)
+				address, err := evmToBech32(networkConfig.Bech32Prefix, w.Address)
 				if err != nil {
-					fmt.Println(err)
-					continue
+					return nil, fmt.Errorf("%s %s: %w", networkConfig.Name, w.Name, err)
 				}
-
-				icxBalance := toDecimalUnit(balance, networkConfig.Decimals)
-				fmt.Printf(prettyFormat, wallet.Address, icxBalance.String(), balance.String(), threshold.String())
-				if exceedsBalanceThreshold(icxBalance, threshold) {
-					sendAlert(networkConfig.Name, wallet.Name, wallet.Address, icxBalance.String(), threshold.String(), coinName, networkConfig.Explorer)
+				chainCfg.Chains[i].Wallets[j].Address = address
+			}
+		}
+		if networkConfig.CriticalThreshold != "" {
+			chainCfg.Chains[i].Threshold = networkConfig.CriticalThreshold
+		}
+		for _, threshold := range []string{networkConfig.WarningThreshold, networkConfig.Dust} {
+			if _, ok := new(big.Float).SetString(threshold); threshold != "" && !ok {
+				return nil, fmt.Errorf("%s: invalid threshold %q", networkConfig.Name, threshold)
+			}
+		}
+		if err := chainCfg.Chains[i].validateThresholds(Wallet{}); err != nil {
+			return nil, fmt.Errorf("%s: %w", networkConfig.Name, err)
+		}
+		if err := validateEscalation(networkConfig.Escalation, chainCfg.Contacts); err != nil {
+			return nil, fmt.Errorf("%s: %w", networkConfig.Name, err)
+		}
+		if _, _, err := networkConfig.schedule(Wallet{}, time.Minute); err != nil {
+			return nil, err
+		}
+		if networkConfig.MaxConcurrency < 0 {
+			return nil, fmt.Errorf("%s: invalid max concurrency %d", networkConfig.Name, networkConfig.MaxConcurrency)
+		}
+		if networkConfig.MaxAttempts < 0 || networkConfig.BreakerFailures < 0 {
+			return nil, fmt.Errorf("%s: invalid max attempts or breaker failures", networkConfig.Name)
+		}
+		for _, t := range []string{networkConfig.Timeout, networkConfig.CallTimeout, networkConfig.BreakerCooldown} {
+			if d, err := time.ParseDuration(t); t != "" && (err != nil || d <= 0) {
+				return nil, fmt.Errorf("%s: invalid timeout %q", networkConfig.Name, t)
+			}
+		}
+		for _, w := range networkConfig.Wallets {
+			if _, _, err := networkConfig.schedule(w, time.Minute); err != nil {
+				return nil, err
+			}
+			if err := validateEscalation(w.Escalation, chainCfg.Contacts); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", networkConfig.Name, w.Name, err)
+			}
+			for _, threshold := range []string{w.WarningThreshold, w.CriticalThreshold, w.Dust} {
+				if _, ok := new(big.Float).SetString(threshold); threshold != "" && !ok {
+					return nil, fmt.Errorf("%s %s: invalid threshold %q", networkConfig.Name, w.Name, threshold)
 				}
 			}
+			if err := chainCfg.Chains[i].validateThresholds(w); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", networkConfig.Name, w.Name, err)
+			}
+			if w.Kind != "" && (w.Kind != walletKindContract || networkConfig.Type != "evm") {
+				return nil, fmt.Errorf("%s %s: unsupported wallet kind %q", networkConfig.Name, w.Name, w.Kind)
+			}
+		}
+	}
+	for severity, name := range chainCfg.SeverityContacts {
+		if _, ok := chainCfg.Contacts[name]; !ok {
+			return nil, fmt.Errorf("unknown contact %q of %s alerts", name, severity)
+		}
+	}
+	if chainCfg.RateLimit != nil {
+		if err := chainCfg.RateLimit.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := chainCfg.validateChannels(); err != nil {
+		return nil, err
+	}
+	if err := chainCfg.parseTemplates(); err != nil {
+		return nil, err
+	}
+	return &chainCfg, nil
+}
 
-		case "cosmos":
-			for _, wallet := range networkConfig.Wallets {
-				if !wallet.Alert {
-					continue
-				}
-				balance, err := getCosmosBalance(networkConfig.RPC, wallet.Address, networkConfig.Coin)
-				if err != nil {
-					fmt.Println(err)
-					continue
-				}
+// fetch balances of all alert enabled wallets of a network, wallets that
+// fail on one rpc url are retried on the next; tick is called after each
+// wallet of the first attempt if not nil
+func fetchBalances(ctx context.Context, networkConfig NetworkConfig, tick func()) ([]WalletBalance, error) {
+	endpoints := networkConfig.endpoints()
+
+	var served, failed []WalletBalance
+	var err error
+	pending := networkConfig.Wallets
+	for i, endpoint := range endpoints {
+		if i > 0 {
+			slog.Warn("Failing over", "chain", networkConfig.Name, "wallets", len(pending), "endpoint", endpoint)
+			tick = nil
+		}
+		// endpoints known to be down are skipped until their cool-down ends
+		if breakerErr := breakerError(endpoint, time.Now()); breakerErr != nil {
+			err = fmt.Errorf("%s: %w", endpoint, breakerErr)
+			continue
+		}
+		nc := networkConfig
+		nc.RPC = endpoint
+		nc.Wallets = pending
+		var results []WalletBalance
+		results, err = fetchBalancesFrom(ctx, nc, tick)
+		if err != nil {
+			continue
+		}
+		pending, failed = nil, nil
+		for _, r := range results {
+			r.Endpoint = endpoint
+			if r.Err != nil {
+				pending = append(pending, r.Wallet)
+				failed = append(failed, r)
+				continue
+			}
+			served = append(served, r)
+		}
+		if len(pending) == 0 {
+			break
+		}
+	}
+	if len(served) == 0 && len(failed) == 0 && err != nil {
+		return nil, err
+	}
+	return append(served, failed...), nil
+}
 
-				icxBalance := toDecimalUnit(balance, networkConfig.Decimals)
-				fmt.Printf(prettyFormat, wallet.Address, icxBalance.String(), balance.String(), threshold.String())
-				if exceedsBalanceThreshold(icxBalance, threshold) {
-					sendAlert(networkConfig.Name, wallet.Name, wallet.Address, icxBalance.String(), threshold.String(), coinName, networkConfig.Explorer)
-				}
+// fetch balances from the network's current rpc url
+func fetchBalancesFrom(ctx context.Context, networkConfig NetworkConfig, tick func()) ([]WalletBalance, error) {
+	var fetch func(ctx context.Context, address string) (*big.Int, error)
+	switch networkConfig.Type {
+	case "evm":
+		client, err := rpc.DialContext(ctx, networkConfig.RPC)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getETHBalance(ctx, client, address)
+		}
+
+	case "icon":
+		if networkConfig.NetworkID != "" {
+			if err := checkICONNetworkID(ctx, networkConfig.RPC, networkConfig.NetworkID); err != nil {
+				return nil, err
 			}
 		}
-		fmt.Printf("\n\n")
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getICXBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "cosmos":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			var balance *big.Int
+			var err error
+			if networkConfig.GRPC != "" {
+				balance, err = getCosmosBalanceGRPC(ctx, networkConfig.GRPC, address, networkConfig.denom())
+			} else {
+				balance, err = getCosmosBalance(ctx, networkConfig.RPC, address, networkConfig.Coin)
+			}
+			// fall back to the tendermint rpc when the lcd or grpc endpoint fails
+			if err != nil && networkConfig.TendermintRPC != "" {
+				slog.Warn("Falling back to tendermint rpc", "chain", networkConfig.Name, "address", address, "err", err)
+				return getCosmosBalanceABCI(ctx, networkConfig.TendermintRPC, address, networkConfig.denom())
+			}
+			return balance, err
+		}
+
+	case "solana":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getSolanaBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "sui":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getSuiBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "stellar":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getStellarBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "aptos":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getAptosBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "substrate":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getSubstrateBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "tron":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getTronBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "utxo":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getUTXOBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "near":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getNearBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "algorand":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getAlgorandBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "xrpl":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getXRPLBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "starknet":
+		token, err := networkConfig.starknetToken()
+		if err != nil {
+			return nil, err
+		}
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getStarknetBalance(ctx, networkConfig.RPC, token, address)
+		}
+
+	case "ton":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getTONBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "hedera":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getHederaBalance(ctx, networkConfig.RPC, address)
+		}
+
+	case "cardano":
+		fetch = func(ctx context.Context, address string) (*big.Int, error) {
+			return getCardanoBalance(ctx, networkConfig, address)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported network type: %s", networkConfig.Type)
+	}
+
+	var results []WalletBalance
+	for _, wallet := range networkConfig.Wallets {
+		if wallet.Alert {
+			results = append(results, WalletBalance{Wallet: wallet})
+		}
+	}
+	query := networkConfig.withBreaker(networkConfig.RPC, func(ctx context.Context, address string) (*big.Int, error) {
+		return networkConfig.fetchWithRetry(ctx, address, fetch)
+	})
+	var g errgroup.Group
+	g.SetLimit(networkConfig.maxConcurrency())
+	for i := range results {
+		g.Go(func() error {
+			balance, err := query(ctx, results[i].Wallet.Address)
+			if tick != nil {
+				tick()
+			}
+			if err != nil {
+				results[i].Err = err
+				return nil
+			}
+			results[i].Raw = balance
+			results[i].Balance = toDecimalUnit(balance, networkConfig.Decimals)
+			return nil
+		})
 	}
+	g.Wait()
+	return results, nil
+}
+
+// balance queries sent to an rpc url at once
+const defaultMaxConcurrency = 4
+
+func (n NetworkConfig) maxConcurrency() int {
+	return cmp.Or(n.MaxConcurrency, defaultMaxConcurrency)
 }
 
-func getCosmosBalance(rpc, address, denom string) (*big.Int, error) {
+const (
+	// time a network's check may take
+	defaultChainTimeout = 30 * time.Second
+	// time each balance query of the check may take
+	defaultCallTimeout = 10 * time.Second
+)
+
+func (n NetworkConfig) chainTimeout() time.Duration {
+	if d, err := time.ParseDuration(n.Timeout); err == nil {
+		return d
+	}
+	return defaultChainTimeout
+}
+
+func (n NetworkConfig) callTimeout() time.Duration {
+	if d, err := time.ParseDuration(n.CallTimeout); err == nil {
+		return d
+	}
+	return defaultCallTimeout
+}
+
+func getCosmosBalance(ctx context.Context, rpc, address, denom string) (*big.Int, error) {
 	apiURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", rpc, address)
 
-	response, err := http.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		fmt.Println("Error making HTTP request:", err)
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		slog.Debug("Cosmos balance request failed", "address", address, "err", err)
 		return nil, err
 	}
 	defer response.Body.Close()
+	// rate limited or failing lcds are retried
+	if response.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: response.StatusCode}
+	}
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		fmt.Println("Error reading response body:", err)
+		slog.Debug("Reading cosmos balance failed", "address", address, "err", err)
 		return nil, err
 	}
 
 	var cb CosmosBalance
 	if err := json.Unmarshal(body, &cb); err != nil {
-		fmt.Println("Error unmarshalling JSON response:", err)
-		fmt.Println(string(body))
+		slog.Debug("Unmarshalling cosmos balance failed", "address", address, "body", string(body), "err", err)
 		return nil, err
 	}
 	for _, c := range cb.Balances {
@@ -195,20 +775,19 @@ func getCosmosBalance(rpc, address, denom string) (*big.Int, error) {
 	return nil, fmt.Errorf("no balance found for %s", denom)
 }
 
-func getICXBalance(client *iconclient.ClientV3, address string) (*big.Int, error) {
-	bal, err := client.GetBalance(&v3.AddressParam{
-		Address: jsonrpc.Address(address),
-	})
-	if err != nil {
+func getICXBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	var balanceHex string
+	if err := callJSONRPC(ctx, rpcURL, "icx_getBalance", map[string]string{"address": address}, &balanceHex); err != nil {
 		return nil, err
 	}
-	return bal.BigInt()
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(balanceHex, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert balance %q to big.Int", balanceHex)
+	}
+	return balance, nil
 }
 
-func getETHBalance(client *rpc.Client, address string) (*big.Int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	defer cancel()
-
+func getETHBalance(ctx context.Context, client *rpc.Client, address string) (*big.Int, error) {
 	ethAddress := common.HexToAddress(address)
 	var balanceHex string
 	err := client.CallContext(ctx, &balanceHex, "eth_getBalance", ethAddress, "latest")
@@ -236,43 +815,126 @@ func exceedsBalanceThreshold(balance *big.Float, threshold *big.Float) bool {
 	return balance.Cmp(threshold) == -1
 }
 
-// send alert if balance is below threshold
-func sendAlert(network, walletName, address, balance, threshold, coin, explorer string) {
-	message := fmt.Sprintf("🚨 **%s** Alert 🚨\n\nWallet: %s\nAddress: [%s](%s/%s)\nBalance: %s %s\nThreshold: %s %s\n\n", network, walletName, address, explorer, address, balance, coin, threshold, coin)
-	sendDiscordAlert(message)
+// alert deliveries in flight, runs wait for them before they end
+var deliveries sync.WaitGroup
+
+// send alert to all configured channels in the background, so that the
+// retries of a failing channel hold up neither the others nor the checks
+func sendAlert(chainCfg *ChainConfig, alert Alert) {
+	for _, n := range notifiers(chainCfg) {
+		deliveries.Add(1)
+		go func() {
+			defer deliveries.Done()
+			deliver(n, alert)
+		}()
+	}
+}
+
+// send the alert through a channel and record the outcome, the channels
+// retry their own failed sends
+func deliver(n notifier, alert Alert) {
+	if err := n.notify(alert); err != nil {
+		alertErrors.WithLabelValues(alert.Network, alert.Severity).Inc()
+		slog.Error("Giving up sending alert", "chain", alert.Network, "wallet", cmp.Or(alert.Wallet, alert.Target), "err", err)
+		return
+	}
+	alertsSent.WithLabelValues(alert.Network, alert.Severity).Inc()
+	slog.Info("Alert sent", "chain", alert.Network, "wallet", cmp.Or(alert.Wallet, alert.Target), "event", alertEvents[alert.Kind], "severity", alert.Severity)
+}
+
+// retry a failed send with exponential backoff and jitter
+func retrySend(send func() error) error {
+	backoff := alertBackoff
+	for attempt := 1; ; attempt++ {
+		err := send()
+		if err == nil || attempt > alertRetries {
+			return err
+		}
+		wait := backoff + rand.N(backoff)
+		slog.Warn("Sending alert failed, retrying", "attempt", attempt, "retry_in", wait.Round(time.Millisecond), "err", err)
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// retry each send of a sink on its own, inside its rate limit so that the
+// retries take no further tokens
+func retried(fn func(alert Alert) error) func(alert Alert) error {
+	return func(alert Alert) error {
+		return retrySend(func() error {
+			return fn(alert)
+		})
+	}
 }
 
-func sendTelegramAlert(message string) error {
+func sendTelegramAlert(chatID, message string) error {
 	msg := TelegramMessage{
-		Text: message,
+		ChatID: chatID,
+		Text:   message,
 	}
 	jsonMsg, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	res, err := http.Post("https://api.telegram.org/bot"+telegramBotToken+"/sendMessage", "application/json", bytes.NewBuffer(jsonMsg))
+	res, err := sinkClient.Post("https://api.telegram.org/bot"+telegramBotToken+"/sendMessage", "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
 		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
 	}
-	return err
+	return nil
 }
 
-func sendDiscordAlert(message string) error {
-	msg := DiscordMessage{
-		Content: message,
-	}
+func sendDiscordAlert(webhookURL string, msg DiscordMessage) error {
 	jsonMsg, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.Post(discordWebhookURL, "application/json", bytes.NewBuffer(jsonMsg))
+	resp, err := sinkClient.Post(webhookURL, "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// webhooks answer 204 unless called with ?wait=true
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// post a message with a file attached
+func sendDiscordFile(message, filename string, content []byte) error {
+	jsonMsg, err := json.Marshal(DiscordMessage{Content: message})
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("payload_json", string(jsonMsg)); err != nil {
+		return err
+	}
+	part, err := w.CreateFormFile("files[0]", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(content); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	resp, err := sinkClient.Post(discordWebhookURL, w.FormDataContentType(), &body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 	return nil
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// room alerts are posted to, the access token is read from the environment
+type MatrixConfig struct {
+	Homeserver string `json:"homeserver"`
+	RoomID     string `json:"room_id"`
+	// defaults to MATRIX_ACCESS_TOKEN
+	AccessTokenEnv string `json:"access_token_env,omitempty"`
+}
+
+type MatrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func (c *MatrixConfig) accessToken() string {
+	if c.AccessTokenEnv != "" {
+		return os.Getenv(c.AccessTokenEnv)
+	}
+	return os.Getenv("MATRIX_ACCESS_TOKEN")
+}
+
+// convert the markdown of chat messages to html
+func markdownHTML(message string) string {
+	var sb strings.Builder
+	rest := message
+	for {
+		loc := markdownLink.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			sb.WriteString(html.EscapeString(rest))
+			break
+		}
+		sb.WriteString(html.EscapeString(rest[:loc[0]]))
+		fmt.Fprintf(&sb, `<a href="%s">%s</a>`, html.EscapeString(rest[loc[4]:loc[5]]), html.EscapeString(rest[loc[2]:loc[3]]))
+		rest = rest[loc[1]:]
+	}
+	out := sb.String()
+	for strings.Count(out, "**") >= 2 {
+		out = strings.Replace(out, "**", "<b>", 1)
+		out = strings.Replace(out, "**", "</b>", 1)
+	}
+	return strings.ReplaceAll(strings.TrimRight(out, "\n"), "\n", "<br>")
+}
+
+func sendMatrixAlert(c *MatrixConfig, message string) error {
+	jsonMsg, err := json.Marshal(MatrixMessage{
+		MsgType:       "m.text",
+		Body:          plainText(message),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: markdownHTML(message),
+	})
+	if err != nil {
+		return err
+	}
+	// the transaction id is shared by the retries of the message, which
+	// makes them idempotent
+	txnID := fmt.Sprintf("balance-tracker-%d", time.Now().UnixNano())
+	apiURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", strings.TrimSuffix(c.Homeserver, "/"), url.PathEscape(c.RoomID), txnID)
+	return retrySend(func() error {
+		req, err := http.NewRequest(http.MethodPut, apiURL, bytes.NewReader(jsonMsg))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.accessToken())
+
+		resp, err := sinkClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+var balanceLabels = []string{"network", "wallet", "address", "coin"}
+
+// gauges of the wallets, the same family for the textfile, the pushgateway
+// and /metrics of daemon mode
+type BalanceGauges struct {
+	Balance, Threshold, Breached, LastCheck *prometheus.GaugeVec
+}
+
+func newBalanceGauges() BalanceGauges {
+	return BalanceGauges{
+		Balance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "balance_tracker_balance",
+			Help: "Wallet balance in display units.",
+		}, balanceLabels),
+		Threshold: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "balance_tracker_threshold",
+			Help: "Alert threshold of the wallet in display units.",
+		}, balanceLabels),
+		Breached: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "balance_tracker_breached",
+			Help: "Whether the wallet balance is below its threshold.",
+		}, balanceLabels),
+		LastCheck: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "balance_tracker_last_check_timestamp_seconds",
+			Help: "Unix time of the wallet's last check.",
+		}, balanceLabels),
+	}
+}
+
+func (g BalanceGauges) collectors() []prometheus.Collector {
+	return []prometheus.Collector{g.Balance, g.Threshold, g.Breached, g.LastCheck}
+}
+
+// set the gauges of a wallet to a sample
+func (g BalanceGauges) record(s Sample) {
+	labels := prometheus.Labels{"network": s.Network, "wallet": s.Wallet, "address": s.Address, "coin": s.Coin}
+	g.Balance.With(labels).Set(s.balance())
+	g.Threshold.With(labels).Set(s.threshold())
+	b := 0.0
+	if s.Breached {
+		b = 1
+	}
+	g.Breached.With(labels).Set(b)
+	g.LastCheck.With(labels).Set(float64(s.Time.Unix()))
+}
+
+// gauges of a run's samples
+func runRegistry(samples []Sample) *prometheus.Registry {
+	gauges := newBalanceGauges()
+	lastRun := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "balance_tracker_last_run_timestamp_seconds",
+		Help: "Unix time of the last run.",
+	})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauges.collectors()...)
+	reg.MustRegister(lastRun, slaRatio)
+	for _, s := range samples {
+		gauges.record(s)
+	}
+	lastRun.Set(float64(time.Now().Unix()))
+	return reg
+}
+
+// share of checks above threshold, one series per wallet and sla window
+var slaRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "balance_tracker_sla_ratio",
+	Help: "Share of the wallet's checks above threshold within the window.",
+}, append(slices.Clone(balanceLabels), "window"))
+
+// set the sla gauges of the sampled wallets from the history
+func recordSLA(path string, latest []Sample) error {
+	now := time.Now().UTC()
+	history, err := readHistory(path, now.Add(-slaWindows[len(slaWindows)-1]))
+	if err != nil {
+		return err
+	}
+	slas := buildSLA(history, now)
+	for _, s := range latest {
+		for i, window := range slaWindows {
+			labels := prometheus.Labels{"network": s.Network, "wallet": s.Wallet, "address": s.Address, "coin": s.Coin, "window": fmt.Sprintf("%dd", int(window.Hours())/24)}
+			ratios, ok := slas[s.key()]
+			if !ok || ratios[i] < 0 {
+				slaRatio.Delete(labels)
+				continue
+			}
+			slaRatio.With(labels).Set(ratios[i])
+		}
+	}
+	return nil
+}
+
+// write the gauges for the node_exporter textfile collector, the file is
+// replaced atomically so a scrape never sees it half written
+func writePromTextfile(path string, samples []Sample) error {
+	return prometheus.WriteToTextfile(path, runRegistry(samples))
+}
+
+// push the gauges of a run with its duration and the error counters, the
+// push replaces the metrics of the job's previous one
+func pushMetrics(url string, samples []Sample, duration time.Duration) error {
+	reg := runRegistry(samples)
+	runDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "balance_tracker_run_duration_seconds",
+		Help: "Duration of the last run.",
+	})
+	runDuration.Set(duration.Seconds())
+	reg.MustRegister(runDuration, rpcErrors, alertsSent, alertErrors)
+	return push.New(url, *pushgatewayJob).Client(sinkClient).Gatherer(reg).Push()
+}
+
+// metrics of the daemon, served on /metrics
+var (
+	daemonGauges = newBalanceGauges()
+	// endpoints are labelled by host, urls may carry api keys
+	rpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "balance_tracker_rpc_errors_total",
+		Help: "Failed balance queries, retries included.",
+	}, []string{"network", "endpoint"})
+	alertsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "balance_tracker_alerts_sent_total",
+		Help: "Alerts delivered to a sink.",
+	}, []string{"network", "severity"})
+	alertErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "balance_tracker_alert_errors_total",
+		Help: "Alerts a sink failed to take after all retries.",
+	}, []string{"network", "severity"})
+)
+
+func daemonRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(daemonGauges.collectors()...)
+	reg.MustRegister(slaRatio, rpcErrors, alertsSent, alertErrors)
+	return reg
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+// monitor evaluates fetched balances against thresholds and alerts
+type monitor struct {
+	chainCfg *ChainConfig
+	store    *stateStore
+	// alerts held back for the digest while runs are in flight
+	pending []Alert
+	runs    int
+	// guards pending and runs, networks and schedules are checked
+	// concurrently
+	pendingMu sync.Mutex
+	// guards latest, drain and the history and budget writes, runs of different
+	// schedules only take turns recording their results
+	mu sync.Mutex
+	// most recent sample of each wallet
+	latest map[string]Sample
+	// samples of each wallet within the drain window, nil until a drain
+	// check needs them
+	drain map[string][]Sample
+	// mutex of each wallet being evaluated
+	wallets sync.Map
+	health  healthStatus
+}
+
+func newMonitor(chainCfg *ChainConfig) *monitor {
+	return &monitor{
+		chainCfg: chainCfg,
+		store:    newStateStore(chainCfg.statePath()),
+		latest:   make(map[string]Sample),
+	}
+}
+
+// check the networks once, concurrently, and return the recorded samples
+func (m *monitor) run(ctx context.Context, networks []NetworkConfig) []Sample {
+	m.pendingMu.Lock()
+	m.runs++
+	m.pendingMu.Unlock()
+	defer m.flush()
+	// tables are printed in config order once all networks are checked
+	outs := make([]bytes.Buffer, len(networks))
+	results := make([][]Sample, len(networks))
+	var g errgroup.Group
+	for i, networkConfig := range networks {
+		g.Go(func() error {
+			// spinners of concurrent networks would overwrite each other
+			results[i] = m.checkNetwork(ctx, &outs[i], networkConfig, len(networks) == 1)
+			return nil
+		})
+	}
+	g.Wait()
+	var samples []Sample
+	for i := range networks {
+		os.Stdout.Write(outs[i].Bytes())
+		samples = append(samples, results[i]...)
+	}
+	return samples
+}
+
+func (m *monitor) lockWallet(key string) (unlock func()) {
+	mu, _ := m.wallets.LoadOrStore(key, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// record samples as the latest of their wallets and in the history, and
+// return the latest sample of every wallet
+func (m *monitor) record(samples []Sample) []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range samples {
+		m.latest[s.key()] = s
+		daemonGauges.record(s)
+		if m.drain != nil {
+			m.drain[s.key()] = append(m.drain[s.key()], s)
+		}
+	}
+	if err := appendHistory(m.chainCfg.historyPath(), samples); err != nil {
+		slog.Error("Writing history failed", "err", err)
+	}
+	latest := make([]Sample, 0, len(m.latest))
+	for _, s := range m.latest {
+		latest = append(latest, s)
+	}
+	return latest
+}
+
+// check a network and write its table to out
+func (m *monitor) checkNetwork(ctx context.Context, out io.Writer, networkConfig NetworkConfig, spin bool) []Sample {
+	// shutting down
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, networkConfig.chainTimeout())
+	defer cancel()
+	ctx, span := tracer.Start(ctx, "chain", trace.WithAttributes(
+		attribute.String("chain", networkConfig.Name),
+		attribute.String("chain.type", networkConfig.Type),
+		attribute.Int("wallets", len(networkConfig.Wallets)),
+	))
+	defer span.End()
+
+	fmt.Fprintf(out, "Network: %s\n", networkConfig.Name)
+
+	coinName := m.coin(networkConfig)
+	fmt.Fprintf(out, prettyFormat, "Address", fmt.Sprintf("Balance (%s)", coinName), "Balance", "Threshold")
+	fmt.Fprintln(out, strings.Repeat("-", 125))
+	if _, ok := new(big.Float).SetString(networkConfig.Threshold); !ok {
+		slog.Error("Invalid threshold", "chain", networkConfig.Name, "threshold", networkConfig.Threshold)
+		return nil
+	}
+	if networkConfig.PreferBestRPC {
+		m.rankEndpoints(&networkConfig)
+	}
+	probes := make(map[string]endpointProbe)
+	for _, endpoint := range networkConfig.endpoints() {
+		if err := breakerError(endpoint, time.Now()); err != nil {
+			probes[endpoint] = endpointProbe{err: err}
+			continue
+		}
+		probes[endpoint] = probeEndpoint(ctx, networkConfig, endpoint)
+	}
+	var p *progress
+	if spin {
+		p = startProgress(networkConfig)
+	}
+	results, err := fetchBalances(ctx, networkConfig, p.tick)
+	p.stop()
+	// fetches cut short by the shutdown say nothing about the endpoints
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	addStaked(ctx, networkConfig, results)
+	addRewards(ctx, networkConfig, results)
+	endpoints := networkConfig.endpoints()
+	for i, endpoint := range endpoints {
+		m.checkEndpoint(networkConfig, endpoint, probes[endpoint], failedShare(results, err, endpoints, i))
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		slog.Error("Fetching balances failed", "chain", networkConfig.Name, "err", err)
+		return nil
+	}
+	var samples []Sample
+	for _, r := range results {
+		if r.Err != nil {
+			slog.Error("Fetching balance failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", r.Err)
+			continue
+		}
+		critical, _ := networkConfig.thresholds(r.Wallet)
+		fmt.Fprintf(out, prettyFormat, r.Wallet.Address, m.chainCfg.Format.format(r.Balance), r.Raw.String(), m.chainCfg.Format.format(critical))
+		slog.Info("Balance fetched", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "address", r.Wallet.Address, "balance", m.chainCfg.Format.format(r.Balance), "coin", coinName, "endpoint", r.Endpoint)
+		if r.Staked != nil {
+			fmt.Fprintf(out, prettyFormat, "", "staked "+m.chainCfg.Format.format(r.Staked), "", "")
+		}
+		if r.Rewards != nil {
+			fmt.Fprintf(out, prettyFormat, "", "rewards "+m.chainCfg.Format.format(r.Rewards), "", "")
+		}
+		samples = append(samples, m.checkWallet(networkConfig, r))
+	}
+	m.checkTokens(ctx, out, networkConfig)
+	m.checkFeegrants(ctx, out, networkConfig)
+	m.checkAllowances(ctx, out, networkConfig)
+	m.checkFeeSharing(ctx, out, networkConfig)
+	fmt.Fprintf(out, "\n\n")
+	return samples
+}
+
+// share of the wallets that reached the i-th endpoint which it failed,
+// wallets fail over in order so one served or failed by a later endpoint
+// was failed by this one too
+func failedShare(results []WalletBalance, err error, endpoints []string, i int) float64 {
+	if err != nil {
+		return 1
+	}
+	asked, failed := 0, 0
+	for _, r := range results {
+		j := slices.Index(endpoints, r.Endpoint)
+		if j < i {
+			continue
+		}
+		asked++
+		if j > i || r.Err != nil {
+			failed++
+		}
+	}
+	if asked == 0 {
+		return 0
+	}
+	return float64(failed) / float64(asked)
+}
+
+// evaluate a fetched wallet balance and return its history sample, balances
+// between the critical and the warning threshold alert as warnings
+func (m *monitor) checkWallet(networkConfig NetworkConfig, r WalletBalance) Sample {
+	// a re-check and a scheduled check of the wallet evaluate in turn
+	unlock := m.lockWallet(walletKey(networkConfig.Name, r.Wallet.Address))
+	defer unlock()
+	threshold, warning := networkConfig.thresholds(r.Wallet)
+	balance := networkConfig.thresholdBalance(r)
+	breached := exceedsBalanceThreshold(balance, threshold)
+	severity, alertThreshold := severityCritical, threshold
+	if !breached && warning != nil && exceedsBalanceThreshold(balance, warning) {
+		severity, alertThreshold = severityWarning, warning
+	}
+	// an empty wallet is critical whatever its thresholds
+	empty := r.Balance.Sign() == 0
+	if dust := networkConfig.dust(r.Wallet); dust != nil && r.Balance.Cmp(dust) <= 0 {
+		empty = true
+	}
+	if empty {
+		breached, severity = true, severityCritical
+	}
+	// published in the background like the alerts, a slow consumer must
+	// not hold up the checks
+	if balanceWebhookURL != "" {
+		deliveries.Add(1)
+		go func() {
+			defer deliveries.Done()
+			if err := publishBalanceChange(m.store, networkConfig, r); err != nil {
+				slog.Error("Publishing balance change failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+			}
+		}()
+	}
+	delta := m.balanceChange(walletKey(networkConfig.Name, r.Wallet.Address), r.Balance)
+	alert := Alert{
+		Severity:  severity,
+		Network:   networkConfig.Name,
+		Wallet:    r.Wallet.Name,
+		Address:   r.Wallet.Address,
+		Balance:   m.chainCfg.Format.format(r.Balance),
+		Amount:    r.Balance,
+		Limit:     alertThreshold,
+		RawAmount: r.Raw,
+		Empty:     empty,
+		Tier:      breachTier(balance, alertThreshold),
+		Threshold: m.chainCfg.Format.format(alertThreshold),
+		Coin:      m.coin(networkConfig),
+		Explorer:  networkConfig.Explorer,
+		Staked:    m.chainCfg.Format.formatOptional(r.Staked),
+		Rewards:   m.chainCfg.Format.formatOptional(r.Rewards),
+		Contact:   m.chainCfg.contact(networkConfig, r.Wallet.Owner, severity),
+		Channels:  r.Wallet.Channels,
+		Delta:     delta,
+	}
+	if breached || severity == severityWarning {
+		slog.Warn("Breach detected", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "address", r.Wallet.Address, "balance", alert.Balance, "threshold", alert.Threshold, "severity", severity, "empty", empty)
+	}
+	sample := Sample{
+		Time:       time.Now().UTC(),
+		Network:    networkConfig.Name,
+		Wallet:     r.Wallet.Name,
+		Address:    r.Wallet.Address,
+		Coin:       networkConfig.Coin,
+		Balance:    floatString(r.Balance),
+		Endpoint:   r.Endpoint,
+		Staked:     floatString(r.Staked),
+		Rewards:    floatString(r.Rewards),
+		Threshold:  floatString(threshold),
+		Breached:   breached,
+		Suppressed: breached && m.suppressed(networkConfig.Name, r.Wallet.Name, r.Wallet.Address) != nil,
+	}
+	// a critical breach only changes the alert state once the second rpc
+	// confirms it
+	if breached && severity == severityCritical && networkConfig.VerifyRPC != "" {
+		confirmed, detail := m.verify(networkConfig, threshold, r)
+		if !confirmed {
+			m.checkDrain(networkConfig, r)
+			return sample
+		}
+		alert.Detail = detail
+	}
+	rule, err := networkConfig.breachRule(r.Wallet)
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, breached || severity == severityWarning, rule)
+	if err != nil {
+		slog.Error("Recording alert state failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+	}
+	if notify {
+		if alert.Kind == alertBreach {
+			m.enrich(networkConfig, &alert)
+		}
+		m.notify(alert)
+	}
+	m.checkDrain(networkConfig, r)
+	return sample
+}
+
+// change of the balance since the previous check, empty on the first one
+func (m *monitor) balanceChange(key string, balance *big.Float) string {
+	var delta string
+	now := time.Now().UTC()
+	err := m.store.update(func(st *AlertState) error {
+		ws := st.wallet(key)
+		if previous, ok := new(big.Float).SetString(ws.LastBalance); ok {
+			d := new(big.Float).Sub(balance, previous)
+			delta = m.chainCfg.Format.format(d)
+			if d.Sign() > 0 {
+				delta = "+" + delta
+			}
+		}
+		ws.LastBalance = floatString(balance)
+		ws.LastChecked = now
+		return nil
+	})
+	if err != nil {
+		slog.Error("Recording balance failed", "wallet", key, "err", err)
+	}
+	return delta
+}
+
+func (m *monitor) suppressed(network, wallet, address string) *Suppression {
+	st, err := m.store.load()
+	if err != nil {
+		slog.Error("Loading suppressions failed", "err", err)
+		return nil
+	}
+	return m.chainCfg.suppression(st, network, wallet, address, time.Now())
+}
+
+// record the breach state and decide whether the alert is sent, breaches
+// alertmanager knows about are re-sent to it on every run so that it does not
+// resolve them while they cool down or are silenced here
+func (m *monitor) evaluate(alert Alert, breached bool, rule breachRule) (Alert, bool, error) {
+	alert, notify, err := evaluateAlert(m.store, alert, breached, rule)
+	if breached && !notify && err == nil && alertmanagerURL != "" {
+		m.keepFiring(alert, rule)
+	}
+	return alert, notify, err
+}
+
+func (m *monitor) keepFiring(alert Alert, rule breachRule) {
+	st, err := m.store.load()
+	if err != nil {
+		slog.Error("Loading alert state failed", "err", err)
+		return
+	}
+	// pending breaches have not fired yet
+	ws, ok := st.Wallets[alert.key()]
+	if !ok || !ws.Breached || m.chainCfg.suppression(st, alert.Network, alert.Wallet, alert.Address, time.Now()) != nil {
+		return
+	}
+	// same labels as the alert that fired
+	alert.Severity = cmp.Or(ws.Severity, alert.Severity)
+	if ws.Escalation > 0 && ws.Escalation <= len(rule.escalation) {
+		alert.Severity = rule.escalation[ws.Escalation-1].severity(alert.Severity)
+	}
+	if err := sendAlertmanagerAlert(alert); err != nil {
+		slog.Error("Refreshing alertmanager alert failed", "chain", alert.Network, "wallet", cmp.Or(alert.Wallet, alert.Target), "err", err)
+	}
+}
+
+// send an alert unless the breach is suppressed
+func (m *monitor) notify(alert Alert) {
+	if alert.Kind == alertBreach {
+		if s := m.suppressed(alert.Network, alert.Wallet, alert.Address); s != nil {
+			slog.Info("Alert suppressed", "chain", alert.Network, "wallet", alert.Wallet, "until", s.Until.UTC(), "reason", s.Reason)
+			return
+		}
+	}
+	alert.Contact = alert.Contact.merge(m.chainCfg.escalationContact(alert))
+	// empty wallets are not held back for the digest
+	if m.chainCfg.Digest && !(alert.Kind == alertBreach && alert.Empty) {
+		m.pendingMu.Lock()
+		held := m.runs > 0
+		if held {
+			m.pending = append(m.pending, alert)
+		}
+		m.pendingMu.Unlock()
+		if held {
+			return
+		}
+	}
+	sendAlert(m.chainCfg, alert)
+}
+
+// send the alerts held back during the run, runs in flight together share
+// them and the first to end sends them
+func (m *monitor) flush() {
+	m.pendingMu.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.runs--
+	m.pendingMu.Unlock()
+	if len(pending) > 0 {
+		sendDigests(m.chainCfg, pending)
+	}
+	sendSuppressed()
+}
+
+// re-check a critical breach against the second rpc before paging, a
+// balance above threshold there holds the alert back, the returned detail
+// annotates the alert
+func (m *monitor) verify(networkConfig NetworkConfig, threshold *big.Float, r WalletBalance) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	networkConfig.RPC = networkConfig.VerifyRPC
+	networkConfig.RPCs = nil
+	networkConfig.Wallets = []Wallet{r.Wallet}
+	results, err := fetchBalances(ctx, networkConfig, nil)
+	if err == nil && len(results) > 0 {
+		err = results[0].Err
+	}
+	if err != nil {
+		slog.Warn("Verifying balance failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+		return true, "not verified, second source unavailable"
+	}
+	// the stake is only queried from the first source
+	second := results[0]
+	second.Staked = r.Staked
+	diff := new(big.Float).Sub(second.Balance, r.Balance)
+	if exceedsBalanceThreshold(networkConfig.thresholdBalance(second), threshold) {
+		if diff.Sign() == 0 {
+			return true, "confirmed by 2 sources"
+		}
+		return true, fmt.Sprintf("confirmed by 2 sources, second source reports %s %s", m.chainCfg.Format.format(second.Balance), m.coin(networkConfig))
+	}
+	slog.Warn("Breach not confirmed by second source", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "balance", m.chainCfg.Format.format(r.Balance), "second_balance", m.chainCfg.Format.format(second.Balance), "difference", m.chainCfg.Format.format(diff), "coin", networkConfig.Coin)
+	return false, ""
+}
+
+// add context to a breach alert before it is sent
+func (m *monitor) enrich(networkConfig NetworkConfig, alert *Alert) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	txs, err := recentTransactions(ctx, networkConfig, alert.Address, 1)
+	if err != nil {
+		slog.Warn("Fetching last transaction failed", "chain", alert.Network, "wallet", alert.Wallet, "err", err)
+	} else if len(txs) > 0 {
+		alert.LastTx = &txs[0]
+	}
+}
+
+// fetch and check the given wallets of a network right away, alongside the
+// runs of the daemon's schedules, the samples are recorded like theirs
+func (m *monitor) recheck(ctx context.Context, networkConfig NetworkConfig, wallets []Wallet) error {
+	if _, ok := new(big.Float).SetString(networkConfig.Threshold); !ok {
+		return fmt.Errorf("error parsing threshold value of %s", networkConfig.Name)
+	}
+	networkConfig.Wallets = wallets
+	results, err := fetchBalances(ctx, networkConfig, nil)
+	if err != nil {
+		return err
+	}
+	addStaked(ctx, networkConfig, results)
+	addRewards(ctx, networkConfig, results)
+	var samples []Sample
+	for _, r := range results {
+		if r.Err != nil {
+			slog.Error("Fetching balance failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", r.Err)
+			continue
+		}
+		slog.Info("Balance fetched", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "balance", m.chainCfg.Format.format(r.Balance), "coin", networkConfig.Coin)
+		samples = append(samples, m.checkWallet(networkConfig, r))
+	}
+	m.record(samples)
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+type NearAccount struct {
+	Amount string `json:"amount"`
+	Locked string `json:"locked"`
+}
+
+// unstaked balance in yoctoNEAR
+func getNearBalance(ctx context.Context, rpcURL, account string) (*big.Int, error) {
+	params := map[string]string{
+		"request_type": "view_account",
+		"finality":     "final",
+		"account_id":   account,
+	}
+	var res NearAccount
+	if err := callJSONRPC(ctx, rpcURL, "query", params, &res); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(res.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid near amount %q", res.Amount)
+	}
+	return balance, nil
+}
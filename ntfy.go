@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	ntfyURL   = os.Getenv("NTFY_URL")
+	ntfyTopic = os.Getenv("NTFY_TOPIC")
+	// access token of protected topics
+	ntfyToken = os.Getenv("NTFY_TOKEN")
+)
+
+func sendNtfyAlert(alert Alert, message string) error {
+	server := ntfyURL
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(server, "/")+"/"+ntfyTopic, strings.NewReader(plainText(message)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", alert.title())
+	tags, priority := "rotating_light", "default"
+	if alert.Severity == severityCritical {
+		priority = "urgent"
+	}
+	if alert.Kind == alertRecovery {
+		tags, priority = "white_check_mark", "default"
+	}
+	req.Header.Set("Tags", tags)
+	req.Header.Set("Priority", priority)
+	if u := alert.explorerURL(); u != "" {
+		req.Header.Set("Click", u)
+	}
+	if ntfyToken != "" {
+		req.Header.Set("Authorization", "Bearer "+ntfyToken)
+	}
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
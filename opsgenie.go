@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+var (
+	opsgenieAPIKey = os.Getenv("OPSGENIE_API_KEY")
+	// https://api.eu.opsgenie.com for eu accounts
+	opsgenieAPIURL = os.Getenv("OPSGENIE_API_URL")
+)
+
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags"`
+	Details     map[string]string `json:"details"`
+}
+
+// opsgenie priority of an alert severity, warnings open as P3 which the
+// default notification rules do not page for
+func opsgeniePriority(severity string) string {
+	if severity == severityCritical {
+		return "P1"
+	}
+	return "P3"
+}
+
+// open an alert on breach and close it again on recovery, both keyed by
+// the alert key so repeated breaches are deduplicated by opsgenie
+func sendOpsgenieAlert(alert Alert) error {
+	switch alert.Kind {
+	case alertBreach:
+		return postOpsgenie("/v2/alerts", OpsgenieAlert{
+			Message:     fmt.Sprintf("%s %s %s below threshold", alert.Network, alert.Wallet, alert.subject()),
+			Alias:       alert.key(),
+			Description: fmt.Sprintf("Balance: %s %s\nThreshold: %s %s\nAddress: %s", alert.Balance, alert.Coin, alert.Threshold, alert.Coin, cmp.Or(alert.explorerURL(), alert.Address)),
+			Priority:    opsgeniePriority(alert.Severity),
+			Source:      "balance-tracker",
+			Tags:        []string{alert.Network, alert.Severity},
+			Details: map[string]string{
+				"chain":   alert.Network,
+				"wallet":  alert.Wallet,
+				"address": alert.Address,
+				"balance": alert.Balance,
+			},
+		})
+	case alertRecovery:
+		path := fmt.Sprintf("/v2/alerts/%s/close?identifierType=alias", url.PathEscape(alert.key()))
+		return postOpsgenie(path, map[string]string{"source": "balance-tracker", "note": "balance back above threshold"})
+	}
+	return nil
+}
+
+func postOpsgenie(path string, body any) error {
+	jsonMsg, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	apiURL := opsgenieAPIURL
+	if apiURL == "" {
+		apiURL = "https://api.opsgenie.com"
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL+path, bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+opsgenieAPIKey)
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// requests are processed asynchronously
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinner on stderr while the wallets of a network are fetched
+type progress struct {
+	network string
+	rpc     string
+	total   int
+	done    atomic.Int32
+	start   time.Time
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// only shown when stderr is a terminal
+func interactive() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// start a spinner for the network, nil when not interactive
+func startProgress(networkConfig NetworkConfig) *progress {
+	if !interactive() {
+		return nil
+	}
+	p := &progress{
+		network: networkConfig.Name,
+		rpc:     networkConfig.RPC,
+		start:   time.Now(),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for _, wallet := range networkConfig.Wallets {
+		if wallet.Alert {
+			p.total++
+		}
+	}
+	go p.spin()
+	return p
+}
+
+func (p *progress) spin() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		select {
+		case <-p.quit:
+			fmt.Fprintf(os.Stderr, "\r\033[K✔ %s %d/%d in %s\n", p.network, p.done.Load(), p.total, p.elapsed())
+			return
+		case <-ticker.C:
+			fmt.Fprintf(os.Stderr, "\r\033[K%c %s %d/%d %s %s", spinnerFrames[frame%len(spinnerFrames)], p.network, p.done.Load(), p.total, p.elapsed(), p.rpc)
+		}
+	}
+}
+
+func (p *progress) elapsed() time.Duration {
+	return time.Since(p.start).Round(100 * time.Millisecond)
+}
+
+// count a fetched wallet, safe to call on a nil progress
+func (p *progress) tick() {
+	if p != nil {
+		p.done.Add(1)
+	}
+}
+
+func (p *progress) stop() {
+	if p == nil {
+		return
+	}
+	close(p.quit)
+	<-p.stopped
+}
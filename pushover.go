@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var (
+	pushoverToken = os.Getenv("PUSHOVER_TOKEN")
+	pushoverUser  = os.Getenv("PUSHOVER_USER")
+	// -2 to 1, empty wallets are always sent with emergency priority
+	pushoverPriority = os.Getenv("PUSHOVER_PRIORITY")
+)
+
+const pushoverEmergency = "2"
+
+func sendPushoverAlert(alert Alert, message string) error {
+	priority := pushoverPriority
+	if priority == "" {
+		priority = "0"
+	}
+	form := url.Values{
+		"token":   {pushoverToken},
+		"user":    {pushoverUser},
+		"title":   {alert.title()},
+		"message": {plainText(message)},
+	}
+	if alert.Kind == alertBreach && alert.Empty {
+		priority = pushoverEmergency
+		// emergency notifications repeat every retry seconds until acknowledged or expired
+		form.Set("retry", "60")
+		form.Set("expire", "3600")
+	}
+	form.Set("priority", priority)
+	if u := alert.explorerURL(); u != "" {
+		form.Set("url", u)
+	}
+
+	resp, err := sinkClient.Post("https://api.pushover.net/1/messages.json", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
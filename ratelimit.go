@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// at most Messages alerts per sink within Per, e.g. 10 per 10m, alerts over
+// the limit are dropped and counted in a summary sent at the end of the run,
+// or once per period by the watch command whose rechecks have no end
+type RateLimit struct {
+	Messages int    `json:"messages"`
+	Per      string `json:"per"`
+}
+
+func (r *RateLimit) validate() error {
+	d, err := time.ParseDuration(r.Per)
+	if err != nil || d <= 0 || r.Messages <= 0 {
+		return fmt.Errorf("invalid rate limit of %d per %q", r.Messages, r.Per)
+	}
+	return nil
+}
+
+// token bucket of a sink, refilled continuously up to the message limit
+type tokenBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+	// sends the summary of suppressed alerts, nil for integrations
+	summary func(alert Alert) error
+}
+
+// buckets live as long as the process so the limit holds across the runs
+// of the daemon, watch and serve commands
+var rateLimits = struct {
+	sync.Mutex
+	buckets map[string]*tokenBucket
+}{buckets: make(map[string]*tokenBucket)}
+
+func (r *RateLimit) allow(sink string, now time.Time) bool {
+	per, _ := time.ParseDuration(r.Per)
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+	b, ok := rateLimits.buckets[sink]
+	if !ok {
+		b = &tokenBucket{tokens: float64(r.Messages), last: now}
+		rateLimits.buckets[sink] = b
+	}
+	b.tokens = min(float64(r.Messages), b.tokens+now.Sub(b.last).Seconds()*float64(r.Messages)/per.Seconds())
+	b.last = now
+	if b.tokens < 1 {
+		b.suppressed++
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// drop alerts of a sink over the rate limit
+func (c *ChainConfig) limit(sink string, fn func(alert Alert) error) func(alert Alert) error {
+	if c.RateLimit == nil {
+		return fn
+	}
+	return func(alert Alert) error {
+		if !c.RateLimit.allow(sink, time.Now()) {
+			slog.Warn("Rate limit reached, alert suppressed", "sink", sink, "chain", alert.Network, "wallet", alert.Wallet)
+			return nil
+		}
+		return fn(alert)
+	}
+}
+
+// like limit, the chat sink posts how many alerts it suppressed
+func (c *ChainConfig) limitChat(sink string, fn func(alert Alert) error) func(alert Alert) error {
+	if c.RateLimit == nil {
+		return fn
+	}
+	rateLimits.Lock()
+	if b, ok := rateLimits.buckets[sink]; ok {
+		b.summary = fn
+	} else {
+		rateLimits.buckets[sink] = &tokenBucket{tokens: float64(c.RateLimit.Messages), last: time.Now(), summary: fn}
+	}
+	rateLimits.Unlock()
+	return c.limit(sink, fn)
+}
+
+// send each chat sink the number of alerts it suppressed since the last summary
+func sendSuppressed() {
+	type summary struct {
+		send  func(alert Alert) error
+		count int
+	}
+	var summaries []summary
+	rateLimits.Lock()
+	for _, b := range rateLimits.buckets {
+		if b.suppressed > 0 && b.summary != nil {
+			summaries = append(summaries, summary{b.summary, b.suppressed})
+		}
+		b.suppressed = 0
+	}
+	rateLimits.Unlock()
+	for _, s := range summaries {
+		deliver(notifierFunc(s.send), Alert{Kind: alertDigest, Severity: severityWarning, Suppressed: s.count})
+	}
+}
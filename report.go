@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"time"
+)
+
+type Report struct {
+	From     time.Time
+	To       time.Time
+	Breaches int
+	Chains   []*ChainReport
+	Budgets  []BudgetStatus
+	RPCs     []EndpointReport
+}
+
+type ChainReport struct {
+	Network  string
+	Coin     string
+	Spent    float64
+	ToppedUp float64
+	Wallets  []*WalletReport
+}
+
+type WalletReport struct {
+	Wallet  string
+	Address string
+	// safe metadata and token holdings of contract wallets
+	Details   []string
+	Balance   float64
+	Staked    float64
+	Rewards   float64
+	Threshold float64
+	Spent     float64
+	ToppedUp  float64
+	TopUps    int
+	Breaches  int
+	Checks    int
+	Breached  bool
+	// the latest breach was suppressed
+	Suppressed bool
+	// average spend per day, zero if not enough history
+	BurnRate float64
+	// days until the balance runs out at the observed spend rate, negative if unknown
+	Runway float64
+	// share of checks above threshold over the last 7 and 30 days
+	SLA []float64
+}
+
+// summarize the history of each wallet between from and to
+func buildReport(samples []Sample, from, to time.Time) *Report {
+	r := &Report{From: from, To: to}
+	chains := make(map[string]*ChainReport)
+	wallets := make(map[string]*WalletReport)
+	first := make(map[string]Sample)
+	last := make(map[string]Sample)
+
+	for _, s := range samples {
+		if s.Time.Before(from) || s.Time.After(to) {
+			continue
+		}
+		cr, ok := chains[s.Network]
+		if !ok {
+			cr = &ChainReport{Network: s.Network, Coin: s.Coin}
+			chains[s.Network] = cr
+			r.Chains = append(r.Chains, cr)
+		}
+		wr, ok := wallets[s.key()]
+		if !ok {
+			wr = &WalletReport{Wallet: s.Wallet, Address: s.Address}
+			wallets[s.key()] = wr
+			first[s.key()] = s
+			cr.Wallets = append(cr.Wallets, wr)
+		}
+
+		if prev, ok := last[s.key()]; ok {
+			delta := s.balance() - prev.balance()
+			if delta < 0 {
+				wr.Spent -= delta
+				cr.Spent -= delta
+			} else if delta > 0 {
+				wr.ToppedUp += delta
+				wr.TopUps++
+				cr.ToppedUp += delta
+			}
+			if s.Breached && !prev.Breached {
+				wr.Breaches++
+				r.Breaches++
+			}
+		} else if s.Breached {
+			wr.Breaches++
+			r.Breaches++
+		}
+		last[s.key()] = s
+
+		wr.Checks++
+		wr.Balance = s.balance()
+		wr.Staked = s.staked()
+		wr.Rewards = s.rewards()
+		wr.Threshold = s.threshold()
+		wr.Breached = s.Breached
+		wr.Suppressed = s.Suppressed
+	}
+
+	for key, wr := range wallets {
+		wr.BurnRate = burnRate(wr.Spent, last[key].Time.Sub(first[key].Time))
+		wr.Runway = runway(wr.Balance, wr.BurnRate)
+	}
+	return r
+}
+
+// spend per day over the period
+func burnRate(spent float64, period time.Duration) float64 {
+	if period < time.Hour {
+		return 0
+	}
+	return spent / period.Hours() * 24
+}
+
+// days until balance is spent at the given burn rate
+func runway(balance, perDay float64) float64 {
+	if perDay <= 0 {
+		return -1
+	}
+	return balance / perDay
+}
+
+func formatRunway(days float64) string {
+	switch {
+	case days < 0:
+		return "n/a"
+	case math.IsInf(days, 1) || days > 365:
+		return "> 1 year"
+	default:
+		return fmt.Sprintf("%.1f days", days)
+	}
+}
+
+// lowest runway across all wallets
+func (r *Report) lowestRunway() (string, float64) {
+	name, lowest := "", -1.0
+	for _, cr := range r.Chains {
+		for _, wr := range cr.Wallets {
+			if wr.Runway >= 0 && (lowest < 0 || wr.Runway < lowest) {
+				name, lowest = cr.Network+"/"+wr.Wallet, wr.Runway
+			}
+		}
+	}
+	return name, lowest
+}
+
+func (r *Report) summary(lang string) string {
+	wallets := 0
+	for _, cr := range r.Chains {
+		wallets += len(cr.Wallets)
+	}
+	msg := fmt.Sprintf("📊 %s %s – %s\n%s: %d\n%s: %d", tr(lang, "funding_report"), r.From.Format(time.DateOnly), r.To.Format(time.DateOnly), tr(lang, "wallet"), wallets, tr(lang, "breaches"), r.Breaches)
+	if name, days := r.lowestRunway(); days >= 0 {
+		msg += fmt.Sprintf("\n%s: %s (%s)", tr(lang, "runway"), name, formatRunway(days))
+	}
+	return msg
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"amount": func(f float64) string { return fmt.Sprintf("%.4f", f) },
+	"runway": formatRunway,
+	"sla":    formatSLA,
+	"pct":    func(f float64) float64 { return f * 100 },
+	"t":      func(key string) string { return tr("", key) },
+	"date":   func(t time.Time) string { return t.Format("2006-01-02 15:04 MST") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{t "funding_report"}} {{date .From}} – {{date .To}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tr.breached td { background: #fde2e2; }
+tr.suppressed td { opacity: 0.5; }
+@media print { body { margin: 0; } h2 { page-break-before: auto; } table { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{t "funding_report"}}</h1>
+<p>{{date .From}} – {{date .To}} &middot; {{t "breaches"}}: {{.Breaches}}</p>
+{{range .Chains}}
+<h2>{{.Network}} ({{.Coin}})</h2>
+<p>{{t "spent"}}: {{amount .Spent}} {{.Coin}} &middot; {{t "topped_up"}}: {{amount .ToppedUp}} {{.Coin}}</p>
+<table>
+<tr><th>{{t "wallet"}}</th><th>{{t "balance"}}</th><th>{{t "threshold"}}</th><th>{{t "spent"}}</th><th>{{t "top_ups"}}</th><th>{{t "breaches"}}</th><th>{{t "runway"}}</th><th>{{t "sla_7d"}}</th><th>{{t "sla_30d"}}</th></tr>
+{{$coin := .Coin}}{{range .Wallets}}
+<tr{{if .Suppressed}} class="breached suppressed"{{else if .Breached}} class="breached"{{end}}><td>{{.Wallet}}<br><small>{{.Address}}</small>{{range .Details}}<br><small>{{.}}</small>{{end}}</td><td>{{amount .Balance}}{{if .Staked}}<br><small>{{t "staked"}} {{amount .Staked}}</small>{{end}}{{if .Rewards}}<br><small>{{t "rewards"}} {{amount .Rewards}}</small>{{end}}</td><td>{{amount .Threshold}}</td><td>{{amount .Spent}}</td><td>{{.TopUps}} ({{amount .ToppedUp}})</td><td>{{.Breaches}}</td><td>{{runway .Runway}}</td>{{range .SLA}}<td>{{sla .}}</td>{{end}}</tr>
+{{end}}
+</table>
+{{end}}
+{{if .Budgets}}
+<h2>{{t "monthly_budget"}}</h2>
+<table>
+<tr><th>{{t "scope"}}</th><th>{{t "spent"}}</th><th>{{t "projected"}}</th><th>{{t "budget"}}</th></tr>
+{{range .Budgets}}
+<tr{{if gt .Projected .Budget}} class="breached"{{end}}><td>{{.Network}} {{or .Wallet (t "all_wallets")}}</td><td>{{amount .Spent}} {{.Coin}}</td><td>{{amount .Projected}} {{.Coin}}</td><td>{{amount .Budget}} {{.Coin}}</td></tr>
+{{end}}
+</table>
+{{end}}
+{{if .RPCs}}
+<h2>RPC endpoints</h2>
+<table>
+<tr><th>Endpoint</th><th>Score</th><th>Errors</th><th>Latency</th><th>Staleness</th><th>Suggestion</th></tr>
+{{range .RPCs}}
+<tr{{if .Suggestion}} class="breached"{{end}}><td>{{.Network}}<br><small>{{.URL}}</small></td><td>{{printf "%.0f" .Score}}</td><td>{{printf "%.1f%%" (pct .ErrorRate)}}</td><td>{{printf "%.0fms" .LatencyMs}}</td><td>{{printf "%.0fs" .StalenessS}}</td><td>{{.Suggestion}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// look up the live details of contract wallets in the report
+func addContractDetails(chainCfg *ChainConfig, r *Report) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, cr := range r.Chains {
+		for _, wr := range cr.Wallets {
+			networkConfig, w, err := chainCfg.findWallet(cr.Network, wr.Address)
+			if err != nil || w.Kind != walletKindContract {
+				continue
+			}
+			wr.Details = contractDetails(ctx, chainCfg.Format, networkConfig, w)
+		}
+	}
+}
+
+// report writes a printable html summary of the recorded history
+func report(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	since := fs.Duration("since", 7*24*time.Hour, "period to report on")
+	out := fs.String("out", "report.html", "file to write the report to, empty to skip")
+	s3 := fs.String("s3", "", "upload the report to s3://bucket/key")
+	discord := fs.Bool("discord", false, "post a summary with the report attached to discord")
+	lang := fs.String("lang", "", "language of the report, defaults to english")
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	to := time.Now().UTC()
+	from := to.Add(-*since)
+	earliest := to.Add(-slaWindows[len(slaWindows)-1])
+	if from.Before(earliest) {
+		earliest = from
+	}
+	samples, err := readHistory(chainCfg.historyPath(), earliest)
+	if err != nil {
+		return err
+	}
+	r := buildReport(samples, from, to)
+	slas := buildSLA(samples, to)
+	for _, cr := range r.Chains {
+		for _, wr := range cr.Wallets {
+			wr.SLA = slas[walletKey(cr.Network, wr.Address)]
+		}
+	}
+
+	monthSamples, err := readHistory(chainCfg.historyPath(), monthStart(to))
+	if err != nil {
+		return err
+	}
+	if r.Budgets, err = buildBudgets(chainCfg, monthSamples, to); err != nil {
+		return err
+	}
+
+	st, err := newStateStore(chainCfg.statePath()).load()
+	if err != nil {
+		return err
+	}
+	r.RPCs = endpointReports(st)
+	addContractDetails(chainCfg, r)
+
+	tmpl, err := reportTemplate.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{"t": func(key string) string { return tr(*lang, key) }})
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("funding-report-%s.html", to.Format(time.DateOnly))
+
+	if *out != "" {
+		if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Report written to %s\n", *out)
+	}
+	if *s3 != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := uploadS3(ctx, *s3, "text/html; charset=utf-8", buf.Bytes()); err != nil {
+			return err
+		}
+		fmt.Printf("Report uploaded to %s\n", *s3)
+	}
+	if *discord {
+		if err := sendDiscordFile(r.summary(*lang), name, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// attempts of a balance query on an rpc url before it fails over
+	defaultFetchAttempts = 3
+	// wait before the second attempt, doubling after each
+	fetchBackoff = 500 * time.Millisecond
+)
+
+func (n NetworkConfig) fetchAttempts() int {
+	return cmp.Or(n.MaxAttempts, defaultFetchAttempts)
+}
+
+// error of an http response with an unexpected status code
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// failures of the endpoint rather than of the query: transport errors,
+// timeouts, rate limits and server errors
+func transient(err error) bool {
+	if err == nil || errors.Is(err, errBreakerOpen) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	code := 0
+	var statusErr *HTTPStatusError
+	var gethErr rpc.HTTPError
+	switch {
+	case errors.As(err, &statusErr):
+		code = statusErr.StatusCode
+	case errors.As(err, &gethErr):
+		code = gethErr.StatusCode
+	}
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// host of an rpc url, the url itself may carry an api key in its path or
+// query and must not reach metrics or traces
+func endpointHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
+
+// query a balance, retrying transient failures such as rate limits and
+// timeouts with exponential backoff and jitter, each attempt within the call
+// timeout
+func (n NetworkConfig) fetchWithRetry(ctx context.Context, address string, fetch func(ctx context.Context, address string) (*big.Int, error)) (*big.Int, error) {
+	ctx, span := tracer.Start(ctx, "balance query", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("chain", n.Name),
+		attribute.String("wallet.address", address),
+		attribute.String("rpc.endpoint", endpointHost(n.RPC)),
+	))
+	defer span.End()
+	backoff := fetchBackoff
+	for attempt := 1; ; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, n.callTimeout())
+		start := time.Now()
+		balance, err := fetch(callCtx, address)
+		cancel()
+		span.SetAttributes(attribute.Int("rpc.attempts", attempt), attribute.Int64("rpc.latency_ms", time.Since(start).Milliseconds()))
+		if err != nil {
+			rpcErrors.WithLabelValues(n.Name, endpointHost(n.RPC)).Inc()
+			span.RecordError(err)
+		}
+		if !transient(err) || attempt >= n.fetchAttempts() || ctx.Err() != nil {
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return balance, err
+		}
+		wait := backoff + rand.N(backoff)
+		slog.Warn("Fetching balance failed, retrying", "chain", n.Name, "address", address, "endpoint", n.RPC, "retry_in", wait.Round(time.Millisecond), "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+type CosmosRewards struct {
+	Total []Balances `json:"total"`
+}
+
+// claimable staking rewards, amounts are decimal coins
+func getCosmosRewards(ctx context.Context, lcd, address, denom string) (*big.Float, error) {
+	var res CosmosRewards
+	if err := getJSON(ctx, fmt.Sprintf("%s/cosmos/distribution/v1beta1/delegators/%s/rewards", lcd, url.PathEscape(address)), &res); err != nil {
+		return nil, err
+	}
+	for _, c := range res.Total {
+		if !strings.EqualFold(c.Denom, denom) {
+			continue
+		}
+		rewards, ok := new(big.Float).SetString(c.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid rewards amount %q", c.Amount)
+		}
+		return rewards, nil
+	}
+	return new(big.Float), nil
+}
+
+// add the claimable rewards to fetched wallets of networks tracking them
+func addRewards(ctx context.Context, networkConfig NetworkConfig, results []WalletBalance) {
+	if !networkConfig.Rewards || networkConfig.Type != "cosmos" {
+		return
+	}
+	decimals := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(networkConfig.Decimals)), nil))
+	for i, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		rewards, err := getCosmosRewards(ctx, networkConfig.RPC, r.Wallet.Address, networkConfig.denom())
+		if err != nil {
+			slog.Error("Fetching rewards failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+			continue
+		}
+		results[i].Rewards = rewards.Quo(rewards, decimals)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// serve handles slack slash commands, alert buttons, telegram commands and
+// the admin api
+func serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "address to listen on")
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	store := newStateStore(chainCfg.statePath())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /slack/commands", handleSlackCommand)
+	mux.HandleFunc("POST /slack/interactions", func(w http.ResponseWriter, r *http.Request) {
+		handleSlackInteraction(store, w, r)
+	})
+	mux.HandleFunc("/api/suppressions", handleSuppressions(chainCfg, store))
+	mux.HandleFunc("POST /telegram/webhook", handleTelegramUpdate(chainCfg, store))
+
+	slog.Info("Listening", "address", *listen)
+	return http.ListenAndServe(*listen, mux)
+}
+
+func handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := verifySlackRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if form.Get("command") != "/relayer-balances" {
+		http.Error(w, "unknown command", http.StatusBadRequest)
+		return
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		writeJSON(w, SlackMessage{ResponseType: "ephemeral", Text: err.Error()})
+		return
+	}
+
+	// slack expects a reply within 3 seconds, balances are posted later
+	network, responseURL := form.Get("text"), form.Get("response_url")
+	go func() {
+		msg := SlackMessage{ResponseType: "ephemeral", Text: slackBalancesText(chainCfg, network)}
+		if err := sendSlackResponse(responseURL, msg); err != nil {
+			slog.Error("Responding to slash command failed", "err", err)
+		}
+	}()
+	writeJSON(w, SlackMessage{ResponseType: "ephemeral", Text: "Fetching balances..."})
+}
+
+func handleSlackInteraction(store *stateStore, w http.ResponseWriter, r *http.Request) {
+	body, err := verifySlackRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var interaction SlackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, action := range interaction.Actions {
+		var text string
+		user := fmt.Sprintf("<@%s>", interaction.User.ID)
+		err := store.update(func(st *AlertState) error {
+			ws := st.wallet(action.Value)
+			switch action.ActionID {
+			case slackActionAck:
+				ws.Acknowledged = true
+				ws.AcknowledgedBy = interaction.User.Username
+				text = fmt.Sprintf("✅ %s acknowledged %s", user, action.Value)
+			case slackActionSnooze:
+				ws.SnoozedUntil = time.Now().Add(slackSnooze)
+				ws.SnoozedBy = interaction.User.Username
+				text = fmt.Sprintf("😴 %s snoozed %s until %s", user, action.Value, ws.SnoozedUntil.UTC().Format(time.RFC1123))
+			default:
+				return fmt.Errorf("unknown action: %s", action.ActionID)
+			}
+			return nil
+		})
+		if err != nil {
+			text = fmt.Sprintf("Error updating alert state: %s", err)
+		}
+		if err := sendSlackResponse(interaction.ResponseURL, SlackMessage{ResponseType: "in_channel", Text: text}); err != nil {
+			slog.Error("Responding to interaction failed", "err", err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Writing response failed", "err", err)
+	}
+}
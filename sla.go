@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var slaWindows = []time.Duration{7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// fraction of checks above threshold per wallet for each sla window,
+// negative when a wallet has no checks in a window
+func buildSLA(samples []Sample, now time.Time) map[string][]float64 {
+	checks := make(map[string][]int)
+	above := make(map[string][]int)
+	for _, s := range samples {
+		if _, ok := checks[s.key()]; !ok {
+			checks[s.key()] = make([]int, len(slaWindows))
+			above[s.key()] = make([]int, len(slaWindows))
+		}
+		for i, window := range slaWindows {
+			if now.Sub(s.Time) > window {
+				continue
+			}
+			checks[s.key()][i]++
+			if !s.Breached {
+				above[s.key()][i]++
+			}
+		}
+	}
+
+	sla := make(map[string][]float64)
+	for key, counts := range checks {
+		sla[key] = make([]float64, len(slaWindows))
+		for i, n := range counts {
+			if n == 0 {
+				sla[key][i] = -1
+				continue
+			}
+			sla[key][i] = float64(above[key][i]) / float64(n)
+		}
+	}
+	return sla
+}
+
+func formatSLA(f float64) string {
+	if f < 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", f*100)
+}
+
+// sla prints the share of checks each wallet was above threshold
+func sla(args []string) error {
+	fs := flag.NewFlagSet("sla", flag.ExitOnError)
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	samples, err := readHistory(chainCfg.historyPath(), now.Add(-slaWindows[len(slaWindows)-1]))
+	if err != nil {
+		return err
+	}
+	slas := buildSLA(samples, now)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Network\tWallet\t7 days\t30 days")
+	for _, networkConfig := range chainCfg.Chains {
+		for _, wallet := range networkConfig.Wallets {
+			s, ok := slas[walletKey(networkConfig.Name, wallet.Address)]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", networkConfig.Name, wallet.Name, formatSLA(s[0]), formatSLA(s[1]))
+		}
+	}
+	return w.Flush()
+}
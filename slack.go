@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	slackBotToken      = os.Getenv("SLACK_BOT_TOKEN")
+	slackChannel       = os.Getenv("SLACK_CHANNEL")
+	slackSigningSecret = os.Getenv("SLACK_SIGNING_SECRET")
+	slackWebhookURL    = os.Getenv("SLACK_WEBHOOK_URL")
+	slackSnooze        = 4 * time.Hour
+)
+
+const (
+	slackActionAck    = "ack"
+	slackActionSnooze = "snooze"
+)
+
+type SlackMessage struct {
+	Channel         string       `json:"channel,omitempty"`
+	Text            string       `json:"text"`
+	Blocks          []SlackBlock `json:"blocks,omitempty"`
+	ResponseType    string       `json:"response_type,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original"`
+}
+
+type SlackBlock struct {
+	Type     string         `json:"type"`
+	Text     *SlackText     `json:"text,omitempty"`
+	Elements []SlackElement `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackElement struct {
+	Type     string     `json:"type"`
+	Text     *SlackText `json:"text,omitempty"`
+	ActionID string     `json:"action_id,omitempty"`
+	Value    string     `json:"value,omitempty"`
+	Style    string     `json:"style,omitempty"`
+}
+
+type SlackInteraction struct {
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+
+// convert the markdown of chat messages to slack mrkdwn
+func slackMarkdown(message string) string {
+	message = markdownLink.ReplaceAllString(message, "<$2|$1>")
+	return strings.ReplaceAll(message, "**", "*")
+}
+
+// post the alert with acknowledge and snooze buttons
+func sendSlackAlert(alert Alert, message, lang string) error {
+	text := withMentions(alert.Contact.slackMentions(), slackMarkdown(message))
+	msg := SlackMessage{
+		Channel: slackChannel,
+		Text:    text,
+		Blocks: []SlackBlock{
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: text}},
+			{Type: "actions", Elements: []SlackElement{
+				{Type: "button", Text: &SlackText{Type: "plain_text", Text: tr(lang, "acknowledge")}, ActionID: slackActionAck, Value: alert.key(), Style: "primary"},
+				{Type: "button", Text: &SlackText{Type: "plain_text", Text: tr(lang, "snooze")}, ActionID: slackActionSnooze, Value: alert.key()},
+			}},
+		},
+	}
+	// nothing to acknowledge once recovered, digests hold several alerts
+	if alert.Kind == alertRecovery || alert.Kind == alertDigest {
+		msg.Blocks = msg.Blocks[:1]
+	}
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/chat.postMessage", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+slackBotToken)
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return err
+	}
+	if !res.OK {
+		return fmt.Errorf("slack error: %s", res.Error)
+	}
+	return nil
+}
+
+// post the alert to an incoming webhook, for workspaces without the bot
+func sendSlackWebhook(webhookURL, message string) error {
+	jsonMsg, err := json.Marshal(SlackMessage{Text: message})
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(webhookURL, "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// reply to a slash command or interaction through its response url
+func sendSlackResponse(responseURL string, msg SlackMessage) error {
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(responseURL, "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// read the request body and check the slack request signature
+func verifySlackRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if slackSigningSecret == "" {
+		return nil, fmt.Errorf("SLACK_SIGNING_SECRET is not set")
+	}
+
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request timestamp")
+	}
+	if time.Since(time.Unix(ts, 0)).Abs() > 5*time.Minute {
+		return nil, fmt.Errorf("stale request timestamp")
+	}
+
+	mac := hmac.New(sha256.New, []byte(slackSigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Slack-Signature"))) {
+		return nil, fmt.Errorf("invalid request signature")
+	}
+	return body, nil
+}
+
+// plain text balance table for the slash command
+func slackBalancesText(chainCfg *ChainConfig, network string) string {
+	var sb strings.Builder
+	for _, networkConfig := range chainCfg.Chains {
+		if network != "" && !strings.EqualFold(network, networkConfig.Name) {
+			continue
+		}
+		threshold, ok := new(big.Float).SetString(networkConfig.Threshold)
+		if !ok {
+			fmt.Fprintf(&sb, "*%s*: error parsing threshold value\n", networkConfig.Name)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		results, err := fetchBalances(ctx, networkConfig, nil)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(&sb, "*%s*: %s\n", networkConfig.Name, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "*%s* (threshold %s %s)\n", networkConfig.Name, chainCfg.Format.format(threshold), networkConfig.Coin)
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Fprintf(&sb, "• %s: %s\n", r.Wallet.Name, r.Err)
+				continue
+			}
+			marker := "✅"
+			if exceedsBalanceThreshold(r.Balance, threshold) {
+				marker = "🚨"
+			}
+			fmt.Fprintf(&sb, "%s %s: %s %s\n", marker, r.Wallet.Name, chainCfg.Format.format(r.Balance), networkConfig.Coin)
+		}
+	}
+	if sb.Len() == 0 {
+		return fmt.Sprintf("No network named %s", network)
+	}
+	return sb.String()
+}
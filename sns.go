@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// arn:aws:sns:<region>:<account>:<topic>
+var snsTopicARN = os.Getenv("SNS_TOPIC_ARN")
+
+// subjects are at most 100 printable ascii characters, accents are
+// stripped from letters and anything else, such as emoji, is dropped
+func snsSubject(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if r >= ' ' && r <= '~' && b.Len() < 100 {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// publish the webhook payload of an alert to the topic, the event is also
+// set as message attribute for subscription filter policies
+func publishSNS(ctx context.Context, alert Alert) error {
+	creds, err := awsCredentialsFromEnv()
+	if err != nil {
+		return err
+	}
+	// the topic's region wins over AWS_REGION
+	if parts := strings.Split(snsTopicARN, ":"); len(parts) == 6 {
+		creds.region = parts[3]
+	}
+	payload := webhookPayload(alert)
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	form := url.Values{
+		"Action":                         {"Publish"},
+		"Version":                        {"2010-03-31"},
+		"TopicArn":                       {snsTopicARN},
+		"Message":                        {string(message)},
+		"MessageAttributes.entry.1.Name": {"event"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {payload.Event},
+	}
+	if subject := snsSubject(fmt.Sprintf("%s %s %s %s", alert.Network, alert.Wallet, alert.subject(), payload.Event)); subject != "" {
+		form.Set("Subject", subject)
+	}
+	body := []byte(form.Encode())
+
+	endpoint := fmt.Sprintf("https://sns.%s.amazonaws.com/", creds.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWSRequest(req, body, "sns", creds, time.Now())
+
+	resp, err := sinkClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
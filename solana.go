@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"math/big"
+)
+
+type SolanaBalance struct {
+	Value uint64 `json:"value"`
+}
+
+// balance in lamports
+func getSolanaBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	var res SolanaBalance
+	if err := callJSONRPC(ctx, rpcURL, "getBalance", []any{address, map[string]string{"commitment": "confirmed"}}, &res); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(res.Value), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// which balance the threshold applies to when staked funds are tracked
+const (
+	stakingLiquid = "liquid"
+	stakingTotal  = "total"
+)
+
+type CosmosDelegations struct {
+	DelegationResponses []struct {
+		Balance Balances `json:"balance"`
+	} `json:"delegation_responses"`
+}
+
+func getCosmosDelegations(ctx context.Context, lcd, address, denom string) (*big.Int, error) {
+	var res CosmosDelegations
+	if err := getJSON(ctx, fmt.Sprintf("%s/cosmos/staking/v1beta1/delegations/%s?pagination.limit=1000", lcd, url.PathEscape(address)), &res); err != nil {
+		return nil, err
+	}
+	staked := new(big.Int)
+	for _, d := range res.DelegationResponses {
+		if !strings.EqualFold(d.Balance.Denom, denom) {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(d.Balance.Amount, 10); ok {
+			staked.Add(staked, amount)
+		}
+	}
+	return staked, nil
+}
+
+type ICONStake struct {
+	Stake    string `json:"stake"`
+	Unstakes []struct {
+		Unstake string `json:"unstake"`
+	} `json:"unstakes"`
+}
+
+func parseHexInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex number %q", s)
+	}
+	return n, nil
+}
+
+// staked icx from the chain score, still unstaking icx counts as staked
+// since it cannot be spent either
+func getICONStake(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	params := map[string]any{
+		"to":       iconChainScore,
+		"dataType": "call",
+		"data": map[string]any{
+			"method": "getStake",
+			"params": map[string]string{"address": address},
+		},
+	}
+	var res ICONStake
+	if err := callJSONRPC(ctx, rpcURL, "icx_call", params, &res); err != nil {
+		return nil, err
+	}
+	staked, err := parseHexInt(res.Stake)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range res.Unstakes {
+		unstake, err := parseHexInt(u.Unstake)
+		if err != nil {
+			return nil, err
+		}
+		staked.Add(staked, unstake)
+	}
+	return staked, nil
+}
+
+func fetchStaked(ctx context.Context, networkConfig NetworkConfig, address string) (*big.Int, error) {
+	switch networkConfig.Type {
+	case "cosmos":
+		return getCosmosDelegations(ctx, networkConfig.RPC, address, networkConfig.denom())
+	case "icon":
+		return getICONStake(ctx, networkConfig.RPC, address)
+	}
+	return nil, fmt.Errorf("staking is not supported on %s networks", networkConfig.Type)
+}
+
+// add the staked balance to fetched wallets of networks tracking it
+func addStaked(ctx context.Context, networkConfig NetworkConfig, results []WalletBalance) {
+	if networkConfig.Staking == "" {
+		return
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		staked, err := fetchStaked(ctx, networkConfig, r.Wallet.Address)
+		if err != nil {
+			slog.Error("Fetching staked balance failed", "chain", networkConfig.Name, "wallet", r.Wallet.Name, "err", err)
+			continue
+		}
+		results[i].Staked = toDecimalUnit(staked, networkConfig.Decimals)
+	}
+}
+
+// critical and warning threshold of a wallet, wallet settings override the
+// network ones, warning is nil unless configured
+func (n NetworkConfig) thresholds(w Wallet) (critical, warning *big.Float) {
+	critical, _ = new(big.Float).SetString(n.Threshold)
+	if w.CriticalThreshold != "" {
+		critical, _ = new(big.Float).SetString(w.CriticalThreshold)
+	}
+	if t := cmp.Or(w.WarningThreshold, n.WarningThreshold); t != "" {
+		warning, _ = new(big.Float).SetString(t)
+	}
+	return critical, warning
+}
+
+// a warning must come before the critical threshold is reached
+func (n NetworkConfig) validateThresholds(w Wallet) error {
+	critical, warning := n.thresholds(w)
+	if critical != nil && warning != nil && warning.Cmp(critical) <= 0 {
+		return fmt.Errorf("warning threshold %s is not above the critical threshold %s", warning.Text('f', -1), critical.Text('f', -1))
+	}
+	return nil
+}
+
+// balances at or below dust count as empty, nil if only zero does
+func (n NetworkConfig) dust(w Wallet) *big.Float {
+	if d := cmp.Or(w.Dust, n.Dust); d != "" {
+		dust, _ := new(big.Float).SetString(d)
+		return dust
+	}
+	return nil
+}
+
+// balance compared against the threshold, liquid unless configured as total
+func (n NetworkConfig) thresholdBalance(r WalletBalance) *big.Float {
+	if n.Staking == stakingTotal && r.Staked != nil {
+		return new(big.Float).Add(r.Balance, r.Staked)
+	}
+	return r.Balance
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// starknet_keccak("balanceOf")
+const starknetBalanceOfSelector = "0x2e4263afad30923c891518314c3c95dbe830a16874e8abc5777a9a20b54c76e"
+
+// fee token contracts by coin
+var starknetFeeTokens = map[string]string{
+	"ETH":  "0x049d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7",
+	"STRK": "0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d",
+}
+
+// starknet has no native balance, fee tokens are erc-20 contracts
+// returning the balance as low and high 128 bit felts
+func getStarknetBalance(ctx context.Context, rpcURL, token, address string) (*big.Int, error) {
+	params := map[string]any{
+		"request": map[string]any{
+			"contract_address":     token,
+			"entry_point_selector": starknetBalanceOfSelector,
+			"calldata":             []string{address},
+		},
+		"block_id": "latest",
+	}
+	var res []string
+	if err := callJSONRPC(ctx, rpcURL, "starknet_call", params, &res); err != nil {
+		return nil, err
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("unexpected balanceOf result %v", res)
+	}
+	low, ok := new(big.Int).SetString(strings.TrimPrefix(res[0], "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid felt %q", res[0])
+	}
+	high, ok := new(big.Int).SetString(strings.TrimPrefix(res[1], "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid felt %q", res[1])
+	}
+	return high.Lsh(high, 128).Add(high, low), nil
+}
+
+// fee token of a starknet network, configured or looked up by coin
+func (n NetworkConfig) starknetToken() (string, error) {
+	if n.FeeToken != "" {
+		return n.FeeToken, nil
+	}
+	token, ok := starknetFeeTokens[strings.ToUpper(n.Coin)]
+	if !ok {
+		return "", fmt.Errorf("no fee token known for %s, set fee_token", n.Coin)
+	}
+	return token, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// alert state shared between runs and the serve command
+type AlertState struct {
+	Wallets      map[string]*WalletState   `json:"wallets"`
+	Endpoints    map[string]*EndpointStats `json:"endpoints,omitempty"`
+	Suppressions []Suppression             `json:"suppressions,omitempty"`
+	DenomTraces  map[string]DenomTrace     `json:"denom_traces,omitempty"`
+}
+
+type WalletState struct {
+	Breached            bool      `json:"breached"`
+	ConsecutiveBreaches int       `json:"consecutive_breaches,omitempty"`
+	BreachedSince       time.Time `json:"breached_since,omitempty"`
+	Acknowledged        bool      `json:"acknowledged,omitempty"`
+	AcknowledgedBy      string    `json:"acknowledged_by,omitempty"`
+	SnoozedUntil        time.Time `json:"snoozed_until,omitempty"`
+	SnoozedBy           string    `json:"snoozed_by,omitempty"`
+	LastRawBalance      string    `json:"last_raw_balance,omitempty"`
+	BudgetAlerted       string    `json:"budget_alerted,omitempty"`
+	LastAlerted         time.Time `json:"last_alerted,omitempty"`
+	AlertedTier         int       `json:"alerted_tier,omitempty"`
+	Severity            string    `json:"severity,omitempty"`
+	LastBalance         string    `json:"last_balance,omitempty"`
+	LastChecked         time.Time `json:"last_checked,omitempty"`
+	Empty               bool      `json:"empty,omitempty"`
+	Escalation          int       `json:"escalation,omitempty"`
+}
+
+func walletKey(network, address string) string {
+	return network + "/" + address
+}
+
+func (s *AlertState) wallet(key string) *WalletState {
+	if s.Wallets == nil {
+		s.Wallets = make(map[string]*WalletState)
+	}
+	ws, ok := s.Wallets[key]
+	if !ok {
+		ws = &WalletState{}
+		s.Wallets[key] = ws
+	}
+	return ws
+}
+
+// check if alerts for the wallet are acknowledged or snoozed
+func (w *WalletState) silenced(now time.Time) bool {
+	return w.Acknowledged || now.Before(w.SnoozedUntil)
+}
+
+type stateStore struct {
+	mu   sync.Mutex
+	path string
+	// state as last read or written, valid while the file is unchanged
+	cached  *AlertState
+	modTime time.Time
+}
+
+func newStateStore(path string) *stateStore {
+	return &stateStore{path: path}
+}
+
+// copy of the state, safe to read while others update it
+func (s *stateStore) load() (*AlertState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	clone := &AlertState{}
+	return clone, json.Unmarshal(content, clone)
+}
+
+// load the state, apply fn and write it back
+func (s *stateStore) update(fn func(*AlertState) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		// fn may have changed the cached state halfway
+		s.cached = nil
+		return err
+	}
+	return s.write(st)
+}
+
+// the cached state unless another process, such as the snooze command,
+// changed the file since
+func (s *stateStore) read() (*AlertState, error) {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &AlertState{Wallets: make(map[string]*WalletState)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.cached != nil && info.ModTime().Equal(s.modTime) {
+		return s.cached, nil
+	}
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	st := &AlertState{Wallets: make(map[string]*WalletState)}
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, err
+	}
+	s.cached, s.modTime = st, info.ModTime()
+	return st, nil
+}
+
+func (s *stateStore) write(st *AlertState) error {
+	content, err := json.MarshalIndent(st, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.cached, s.modTime = st, info.ModTime()
+	}
+	return nil
+}
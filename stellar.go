@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// base reserve in stroops, 0.5 XLM since protocol 11
+const stellarBaseReserve = 5_000_000
+
+type StellarAccount struct {
+	SubentryCount int `json:"subentry_count"`
+	NumSponsoring int `json:"num_sponsoring"`
+	NumSponsored  int `json:"num_sponsored"`
+	Balances      []struct {
+		Balance            string `json:"balance"`
+		AssetType          string `json:"asset_type"`
+		SellingLiabilities string `json:"selling_liabilities"`
+	} `json:"balances"`
+}
+
+// parse a horizon amount with 7 decimals into stroops
+func parseStroops(amount string) (*big.Int, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	frac = (frac + "0000000")[:7]
+	stroops, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid stellar amount %q", amount)
+	}
+	return stroops, nil
+}
+
+// spendable XLM in stroops, the balance less the account's minimum
+// balance and what is locked in open offers
+func getStellarBalance(ctx context.Context, horizon, address string) (*big.Int, error) {
+	var account StellarAccount
+	if err := getJSON(ctx, fmt.Sprintf("%s/accounts/%s", horizon, url.PathEscape(address)), &account); err != nil {
+		return nil, err
+	}
+	for _, b := range account.Balances {
+		if b.AssetType != "native" {
+			continue
+		}
+		balance, err := parseStroops(b.Balance)
+		if err != nil {
+			return nil, err
+		}
+		if b.SellingLiabilities != "" {
+			liabilities, err := parseStroops(b.SellingLiabilities)
+			if err != nil {
+				return nil, err
+			}
+			balance.Sub(balance, liabilities)
+		}
+		entries := 2 + account.SubentryCount + account.NumSponsoring - account.NumSponsored
+		balance.Sub(balance, big.NewInt(int64(entries)*stellarBaseReserve))
+		if balance.Sign() < 0 {
+			balance.SetInt64(0)
+		}
+		return balance, nil
+	}
+	return nil, fmt.Errorf("no native balance found")
+}
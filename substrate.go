@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// twox128("System") ++ twox128("Account")
+const systemAccountPrefix = "26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9"
+
+// public key of an ss58 address, hex public keys are accepted as is
+func ss58PublicKey(address string) ([]byte, error) {
+	if strings.HasPrefix(address, "0x") {
+		return hex.DecodeString(address[2:])
+	}
+	data, err := base58Decode(address)
+	if err != nil {
+		return nil, err
+	}
+	prefixLen := 1
+	if len(data) > 0 && data[0]&0x40 != 0 {
+		prefixLen = 2
+	}
+	if len(data) != prefixLen+32+2 {
+		return nil, errors.New("invalid ss58 address length")
+	}
+	payload, checksum := data[:prefixLen+32], data[prefixLen+32:]
+	hash := blake2b.Sum512(append([]byte("SS58PRE"), payload...))
+	if !bytes.Equal(hash[:2], checksum) {
+		return nil, errors.New("invalid ss58 checksum")
+	}
+	return payload[prefixLen:], nil
+}
+
+// storage key of System.Account, a blake2_128_concat map
+func systemAccountKey(pubkey []byte) (string, error) {
+	h, err := blake2b.New(16, nil)
+	if err != nil {
+		return "", err
+	}
+	h.Write(pubkey)
+	return "0x" + systemAccountPrefix + hex.EncodeToString(h.Sum(nil)) + hex.EncodeToString(pubkey), nil
+}
+
+// free balance in planck from the account's System.Account storage
+func getSubstrateBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	pubkey, err := ss58PublicKey(address)
+	if err != nil {
+		return nil, err
+	}
+	key, err := systemAccountKey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	var storage *string
+	if err := callJSONRPC(ctx, rpcURL, "state_getStorage", []any{key}, &storage); err != nil {
+		return nil, err
+	}
+	// accounts without storage were never funded
+	if storage == nil {
+		return new(big.Int), nil
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(*storage, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	// nonce, consumers, providers and sufficients are u32s, followed by the u128 free balance
+	if len(data) < 32 {
+		return nil, errors.New("unexpected account info length")
+	}
+	free := slices.Clone(data[16:32])
+	slices.Reverse(free)
+	return new(big.Int).SetBytes(free), nil
+}
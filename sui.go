@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const suiCoinType = "0x2::sui::SUI"
+
+type SuiBalance struct {
+	CoinType     string `json:"coinType"`
+	TotalBalance string `json:"totalBalance"`
+}
+
+// sui addresses are 32 bytes, shorthand forms are zero padded
+func normalizeSuiAddress(address string) string {
+	return "0x" + fmt.Sprintf("%064s", strings.ToLower(strings.TrimPrefix(address, "0x")))
+}
+
+// balance in mist
+func getSuiBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	var res SuiBalance
+	if err := callJSONRPC(ctx, rpcURL, "suix_getBalance", []any{normalizeSuiAddress(address), suiCoinType}, &res); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(res.TotalBalance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid sui balance %q", res.TotalBalance)
+	}
+	return balance, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var adminToken = os.Getenv("ADMIN_TOKEN")
+
+// breaches of a suppressed wallet are recorded but not alerted
+type Suppression struct {
+	// network is optional, wallet matches a wallet name or address
+	Network string    `json:"network,omitempty"`
+	Wallet  string    `json:"wallet"`
+	Until   time.Time `json:"until"`
+	Reason  string    `json:"reason,omitempty"`
+	By      string    `json:"by,omitempty"`
+}
+
+func (s Suppression) matches(network, wallet, address string, now time.Time) bool {
+	if !now.Before(s.Until) {
+		return false
+	}
+	if s.Network != "" && !strings.EqualFold(s.Network, network) {
+		return false
+	}
+	return strings.EqualFold(s.Wallet, wallet) || strings.EqualFold(s.Wallet, address)
+}
+
+// suppression with where it is defined, only those of the state can be
+// removed
+type SourcedSuppression struct {
+	Suppression
+	Source string `json:"source"`
+}
+
+// active suppressions of the config and the state
+func (c *ChainConfig) activeSuppressions(st *AlertState, now time.Time) []SourcedSuppression {
+	active := []SourcedSuppression{}
+	for _, source := range []struct {
+		name string
+		list []Suppression
+	}{{"config", c.Suppressions}, {"state", st.Suppressions}} {
+		for _, s := range source.list {
+			if now.Before(s.Until) {
+				active = append(active, SourcedSuppression{s, source.name})
+			}
+		}
+	}
+	return active
+}
+
+// active suppression of a wallet from the config or the state
+func (c *ChainConfig) suppression(st *AlertState, network, wallet, address string, now time.Time) *Suppression {
+	for _, s := range append(c.Suppressions, st.Suppressions...) {
+		if s.matches(network, wallet, address, now) {
+			return &s
+		}
+	}
+	return nil
+}
+
+// add a suppression, replacing an earlier one of the wallet and dropping expired ones
+func addSuppression(store *stateStore, s Suppression) error {
+	if s.Wallet == "" {
+		return errors.New("wallet is required")
+	}
+	return store.update(func(st *AlertState) error {
+		now := time.Now()
+		kept := []Suppression{s}
+		for _, old := range st.Suppressions {
+			if now.Before(old.Until) && (!strings.EqualFold(old.Wallet, s.Wallet) || !strings.EqualFold(old.Network, s.Network)) {
+				kept = append(kept, old)
+			}
+		}
+		st.Suppressions = kept
+		return nil
+	})
+}
+
+func removeSuppression(store *stateStore, network, wallet string) error {
+	return store.update(func(st *AlertState) error {
+		var kept []Suppression
+		for _, s := range st.Suppressions {
+			if !strings.EqualFold(s.Wallet, wallet) || (network != "" && !strings.EqualFold(s.Network, network)) {
+				kept = append(kept, s)
+			}
+		}
+		st.Suppressions = kept
+		return nil
+	})
+}
+
+// snooze <wallet> <duration> [reason] suppresses alerts of a wallet
+func snooze(args []string) error {
+	fs := flag.NewFlagSet("snooze", flag.ExitOnError)
+	network := fs.String("network", "", "only suppress the wallet on this network")
+	list := fs.Bool("list", false, "list active suppressions")
+	remove := fs.Bool("remove", false, "remove the suppression of the wallet")
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	store := newStateStore(chainCfg.statePath())
+
+	switch {
+	case *list:
+		st, err := store.load()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Network\tWallet\tUntil\tReason\tSource")
+		for _, s := range chainCfg.activeSuppressions(st, time.Now()) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Network, s.Wallet, s.Until.UTC().Format(time.RFC3339), s.Reason, s.Source)
+		}
+		return w.Flush()
+	case *remove:
+		if fs.NArg() != 1 {
+			return errors.New("usage: snooze -remove [-network name] <wallet>")
+		}
+		return removeSuppression(store, *network, fs.Arg(0))
+	}
+
+	if fs.NArg() < 2 {
+		return errors.New("usage: snooze [-network name] <wallet> <duration> [reason]")
+	}
+	d, err := time.ParseDuration(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	s := Suppression{
+		Network: *network,
+		Wallet:  fs.Arg(0),
+		Until:   time.Now().Add(d).UTC(),
+		Reason:  strings.Join(fs.Args()[2:], " "),
+		By:      os.Getenv("USER"),
+	}
+	if err := addSuppression(store, s); err != nil {
+		return err
+	}
+	fmt.Printf("Suppressed %s until %s\n", s.Wallet, s.Until.Format(time.RFC3339))
+	return nil
+}
+
+// admin api to manage suppressions, requests need the ADMIN_TOKEN bearer token
+func handleSuppressions(chainCfg *ChainConfig, store *stateStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" || r.Header.Get("Authorization") != "Bearer "+adminToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			st, err := store.load()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, chainCfg.activeSuppressions(st, time.Now()))
+		case http.MethodPost:
+			var req struct {
+				Suppression
+				Duration string `json:"duration"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Duration != "" {
+				d, err := time.ParseDuration(req.Duration)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				req.Until = time.Now().Add(d).UTC()
+			}
+			if err := addSuppression(store, req.Suppression); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, req.Suppression)
+		case http.MethodDelete:
+			if err := removeSuppression(store, r.URL.Query().Get("network"), r.URL.Query().Get("wallet")); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+var teamsWebhookURL = os.Getenv("TEAMS_WEBHOOK_URL")
+
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+type AdaptiveCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	Actions []map[string]any `json:"actions,omitempty"`
+}
+
+// adaptive card of an alert with its details as facts
+func teamsCard(alert Alert, lang string) AdaptiveCard {
+	heading, facts := alert.facts(lang)
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]any{
+			{"type": "TextBlock", "text": heading, "weight": "Bolder", "size": "Medium", "color": teamsColor(alert), "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+	if u := alert.explorerURL(); alert.Kind != alertBudget && u != "" {
+		card.Actions = []map[string]any{
+			{"type": "Action.OpenUrl", "title": alert.Address, "url": u},
+		}
+	}
+	return card
+}
+
+func teamsColor(alert Alert) string {
+	if alert.Kind == alertRecovery {
+		return "Good"
+	}
+	return "Attention"
+}
+
+func sendTeamsAlert(webhookURL string, alert Alert, lang string) error {
+	msg := TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{
+			{ContentType: "application/vnd.microsoft.card.adaptive", Content: teamsCard(alert, lang)},
+		},
+	}
+	jsonMsg, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := sinkClient.Post(webhookURL, "application/json", bytes.NewBuffer(jsonMsg))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// workflow webhooks answer 202
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
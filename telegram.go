@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// secret_token the bot webhook was registered with
+var telegramWebhookSecret = os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+
+type TelegramUpdate struct {
+	Message *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From struct {
+			Username string `json:"username"`
+		} `json:"from"`
+	} `json:"message"`
+}
+
+// handle /ack and /snooze commands sent to the bot in the alert chat
+func handleTelegramUpdate(chainCfg *ChainConfig, store *stateStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if telegramWebhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(telegramWebhookSecret)) != 1 {
+			http.Error(w, "invalid secret token", http.StatusUnauthorized)
+			return
+		}
+		var update TelegramUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// telegram retries until it gets a 200, so errors are answered in the chat
+		w.WriteHeader(http.StatusOK)
+		msg := update.Message
+		if msg == nil || strconv.FormatInt(msg.Chat.ID, 10) != telegramChatID {
+			return
+		}
+		reply := telegramCommand(chainCfg, store, msg.From.Username, msg.Text)
+		if reply == "" {
+			return
+		}
+		if err := sendTelegramAlert(telegramChatID, reply); err != nil {
+			slog.Error("Replying to telegram command failed", "err", err)
+		}
+	}
+}
+
+// run a chat command and return the reply, empty for other messages
+func telegramCommand(chainCfg *ChainConfig, store *stateStore, user, text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	// commands in groups may be addressed as /ack@bot
+	command, _, _ := strings.Cut(fields[0], "@")
+	args := fields[1:]
+
+	var until time.Time
+	switch command {
+	case "/ack":
+		if len(args) != 1 {
+			return "Usage: /ack <wallet name or address>"
+		}
+	case "/snooze":
+		if len(args) != 2 {
+			return "Usage: /snooze <duration> <wallet name or address>"
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return fmt.Sprintf("Invalid duration %q", args[0])
+		}
+		until = time.Now().Add(d)
+		args = args[1:]
+	default:
+		return ""
+	}
+
+	keys := chainCfg.matchWallets(args[0])
+	if len(keys) == 0 {
+		return fmt.Sprintf("No wallet matches %s", args[0])
+	}
+	err := store.update(func(st *AlertState) error {
+		for _, key := range keys {
+			st.wallet(key)
+			// checks of the wallet are keyed below the wallet key
+			for k, ws := range st.Wallets {
+				if k != key && !strings.HasPrefix(k, key+"/") {
+					continue
+				}
+				if until.IsZero() {
+					ws.Acknowledged = true
+					ws.AcknowledgedBy = user
+				} else {
+					ws.SnoozedUntil = until
+					ws.SnoozedBy = user
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Sprintf("Error updating alert state: %s", err)
+	}
+	if until.IsZero() {
+		return fmt.Sprintf("✅ @%s acknowledged %s", user, strings.Join(keys, ", "))
+	}
+	return fmt.Sprintf("😴 @%s snoozed %s until %s", user, strings.Join(keys, ", "), until.UTC().Format(time.RFC1123))
+}
+
+// wallet keys of all wallets named like the query, with the address or an
+// address starting with it if it ends in "..."
+func (c *ChainConfig) matchWallets(query string) []string {
+	prefix, abbreviated := strings.CutSuffix(strings.TrimSuffix(query, "…"), "...")
+	abbreviated = abbreviated || strings.HasSuffix(query, "…")
+	var keys []string
+	for _, networkConfig := range c.Chains {
+		for _, w := range networkConfig.Wallets {
+			match := strings.EqualFold(w.Name, query) || strings.EqualFold(w.Address, query)
+			if abbreviated && len(prefix) >= 6 && strings.HasPrefix(strings.ToLower(w.Address), strings.ToLower(prefix)) {
+				match = true
+			}
+			if match {
+				keys = append(keys, walletKey(networkConfig.Name, w.Address))
+			}
+		}
+	}
+	return keys
+}
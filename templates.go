@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+)
+
+// variables of alert templates
+type AlertTemplateData struct {
+	// breach, recovery or budget
+	Event       string
+	Network     string
+	Wallet      string
+	Address     string
+	Balance     string
+	Threshold   string
+	Coin        string
+	ExplorerURL string
+	Severity    string
+	// balance change since the previous check, empty if unknown
+	Delta  string
+	Check  string
+	Detail string
+	// the default message of the alert
+	Message string
+}
+
+// parse the configured alert templates, keyed by channel, channel type or "default"
+func (c *ChainConfig) parseTemplates() error {
+	c.templates = make(map[string]*template.Template)
+	for name, text := range c.Templates {
+		t, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("alert template %s: %w", name, err)
+		}
+		c.templates[name] = t
+	}
+	return nil
+}
+
+func (c *ChainConfig) template(channel string) *template.Template {
+	kind, _, _ := strings.Cut(channel, ":")
+	for _, name := range []string{channel, kind, "default"} {
+		if t, ok := c.templates[name]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// markdown text of an alert on a channel, the built-in message unless a
+// template is configured
+func (c *ChainConfig) alertText(channel string, alert Alert, lang string) string {
+	message := alert.message(lang)
+	t := c.template(channel)
+	if t == nil {
+		return message
+	}
+	// the template renders each alert of a digest
+	if alert.Kind == alertDigest {
+		return alert.digestMessage(lang, func(a Alert, lang string) string {
+			return c.alertText(channel, a, lang)
+		})
+	}
+	data := AlertTemplateData{
+		Event:     alertEvents[alert.Kind],
+		Network:   alert.Network,
+		Wallet:    alert.Wallet,
+		Address:   alert.Address,
+		Balance:   alert.Balance,
+		Threshold: alert.Threshold,
+		Coin:      alert.Coin,
+		Severity:  alert.Severity,
+		Delta:     alert.Delta,
+		Check:     alert.Check,
+		Detail:    alert.Detail,
+		Message:   message,
+	}
+	data.ExplorerURL = alert.explorerURL()
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		slog.Error("Executing alert template failed", "channel", channel, "err", err)
+		return message
+	}
+	return buf.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+type ABCIQueryResult struct {
+	Response struct {
+		Code  uint32 `json:"code"`
+		Log   string `json:"log"`
+		Value []byte `json:"value"`
+	} `json:"response"`
+}
+
+// run a grpc query through the abci_query endpoint of a tendermint rpc
+func abciQuery(ctx context.Context, rpcURL, path string, data []byte) ([]byte, error) {
+	params := map[string]any{"path": path, "data": hex.EncodeToString(data), "prove": false}
+	var res ABCIQueryResult
+	if err := callJSONRPC(ctx, rpcURL, "abci_query", params, &res); err != nil {
+		return nil, err
+	}
+	if res.Response.Code != 0 {
+		return nil, fmt.Errorf("abci query failed with code %d: %s", res.Response.Code, res.Response.Log)
+	}
+	return res.Response.Value, nil
+}
+
+func getCosmosBalanceABCI(ctx context.Context, rpcURL, address, denom string) (*big.Int, error) {
+	res, err := abciQuery(ctx, rpcURL, bankBalanceMethod, bankBalanceRequest(address, denom))
+	if err != nil {
+		return nil, err
+	}
+	return parseBankBalance(res)
+}
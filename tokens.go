@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"strings"
+)
+
+// token balance a wallet must keep next to the native coin
+type TokenConfig struct {
+	Contract  string `json:"contract"`
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+	Threshold string `json:"threshold"`
+}
+
+// balanceOf(address)
+const balanceOfSelector = "0x70a08231"
+
+// tokens of a wallet, wallet entries override network ones of the same contract
+func (n NetworkConfig) walletTokens(w Wallet) []TokenConfig {
+	tokens := append([]TokenConfig(nil), w.Tokens...)
+	for _, t := range n.Tokens {
+		overridden := false
+		for _, wt := range w.Tokens {
+			if strings.EqualFold(wt.Contract, t.Contract) {
+				overridden = true
+			}
+		}
+		if !overridden {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+func getERC20Balance(ctx context.Context, rpcURL, token, address string) (*big.Int, error) {
+	call := map[string]string{
+		"to":   token,
+		"data": balanceOfSelector + abiAddress(address),
+	}
+	var result string
+	if err := callJSONRPC(ctx, rpcURL, "eth_call", []any{call, "latest"}, &result); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid token balance %q", result)
+	}
+	return balance, nil
+}
+
+// balanceOf of an irc-2 token score
+func getIRC2Balance(ctx context.Context, rpcURL, token, address string) (*big.Int, error) {
+	params := map[string]any{
+		"to":       token,
+		"dataType": "call",
+		"data": map[string]any{
+			"method": "balanceOf",
+			"params": map[string]string{"_owner": address},
+		},
+	}
+	var result string
+	if err := callJSONRPC(ctx, rpcURL, "icx_call", params, &result); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid token balance %q", result)
+	}
+	return balance, nil
+}
+
+// balance query of a cw-20 contract through the lcd
+func getCW20Balance(ctx context.Context, lcd, token, address string) (*big.Int, error) {
+	query, err := json.Marshal(map[string]any{"balance": map[string]string{"address": address}})
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Data struct {
+			Balance string `json:"balance"`
+		} `json:"data"`
+	}
+	// url safe base64, a / of the standard alphabet would split the path
+	apiURL := fmt.Sprintf("%s/cosmwasm/wasm/v1/contract/%s/smart/%s", lcd, token, base64.URLEncoding.EncodeToString(query))
+	if err := getJSON(ctx, apiURL, &res); err != nil {
+		return nil, err
+	}
+	balance, ok := new(big.Int).SetString(res.Data.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token balance %q", res.Data.Balance)
+	}
+	return balance, nil
+}
+
+func fetchTokenBalance(ctx context.Context, networkConfig NetworkConfig, token TokenConfig, address string) (*big.Int, error) {
+	switch networkConfig.Type {
+	case "evm":
+		return getERC20Balance(ctx, networkConfig.RPC, token.Contract, address)
+	case "icon":
+		return getIRC2Balance(ctx, networkConfig.RPC, token.Contract, address)
+	case "cosmos":
+		return getCW20Balance(ctx, networkConfig.RPC, token.Contract, address)
+	}
+	return nil, fmt.Errorf("tokens are not supported on %s networks", networkConfig.Type)
+}
+
+// check the token balances of a network's alert enabled wallets
+func (m *monitor) checkTokens(ctx context.Context, out io.Writer, networkConfig NetworkConfig) {
+	for _, w := range networkConfig.Wallets {
+		if !w.Alert {
+			continue
+		}
+		for _, token := range networkConfig.walletTokens(w) {
+			// holdings without a threshold are only reported
+			if token.Threshold == "" {
+				continue
+			}
+			if err := m.checkToken(ctx, out, networkConfig, w, token); err != nil {
+				slog.Error("Checking token balance failed", "chain", networkConfig.Name, "wallet", w.Name, "token", token.Symbol, "err", err)
+			}
+		}
+	}
+}
+
+func (m *monitor) checkToken(ctx context.Context, out io.Writer, networkConfig NetworkConfig, w Wallet, token TokenConfig) error {
+	threshold, ok := new(big.Float).SetString(token.Threshold)
+	if !ok {
+		return fmt.Errorf("error parsing token threshold value")
+	}
+	raw, err := fetchTokenBalance(ctx, networkConfig, token, w.Address)
+	if err != nil {
+		return err
+	}
+	balance := toDecimalUnit(raw, token.Decimals)
+	fmt.Fprintf(out, prettyFormat, w.Address, m.chainCfg.Format.format(balance)+" "+token.Symbol, raw.String(), m.chainCfg.Format.format(threshold))
+
+	alert := Alert{
+		Check:     "token",
+		Target:    token.Contract,
+		Severity:  severityCritical,
+		Network:   networkConfig.Name,
+		Wallet:    w.Name,
+		Address:   w.Address,
+		Balance:   m.chainCfg.Format.format(balance),
+		Threshold: m.chainCfg.Format.format(threshold),
+		Amount:    balance,
+		Limit:     threshold,
+		RawAmount: raw,
+		Coin:      token.Symbol,
+		Explorer:  networkConfig.Explorer,
+		Contact:   m.chainCfg.contact(networkConfig, w.Owner, severityCritical),
+		Channels:  w.Channels,
+	}
+	rule, err := networkConfig.breachRule(w)
+	if err != nil {
+		slog.Error("Invalid breach rule", "chain", networkConfig.Name, "wallet", w.Name, "err", err)
+	}
+	alert, notify, err := m.evaluate(alert, exceedsBalanceThreshold(balance, threshold), rule)
+	if notify {
+		m.notify(alert)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+type TONBalance struct {
+	OK     bool   `json:"ok"`
+	Result string `json:"result"`
+	Error  string `json:"error"`
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// raw workchain:hash form of a ton address, user friendly addresses are
+// base64 with a flags byte, the workchain, the hash and a crc16
+func tonRawAddress(address string) (string, error) {
+	if wc, hash, ok := strings.Cut(address, ":"); ok {
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+			return "", fmt.Errorf("invalid ton address %s", address)
+		}
+		return wc + ":" + strings.ToLower(hash), nil
+	}
+	data, err := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(address))
+	if err != nil || len(data) != 36 {
+		return "", fmt.Errorf("invalid ton address %s", address)
+	}
+	if crc16XModem(data[:34]) != binary.BigEndian.Uint16(data[34:]) {
+		return "", errors.New("invalid ton address checksum")
+	}
+	return fmt.Sprintf("%d:%x", int8(data[1]), data[2:34]), nil
+}
+
+// balance in nanotons from a toncenter compatible api
+func getTONBalance(ctx context.Context, apiURL, address string) (*big.Int, error) {
+	raw, err := tonRawAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	var res TONBalance
+	if err := getJSON(ctx, apiURL+"/getAddressBalance?address="+url.QueryEscape(raw), &res); err != nil {
+		return nil, err
+	}
+	if !res.OK {
+		return nil, fmt.Errorf("toncenter error: %s", res.Error)
+	}
+	balance, ok := new(big.Int).SetString(res.Result, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid ton balance %q", res.Result)
+	}
+	return balance, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// tracing is off unless an otlp/http collector is configured, e.g.
+// OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318, the exporter reads the
+// rest of the OTEL_EXPORTER_OTLP_* variables itself, including the url
+// encoded OTEL_EXPORTER_OTLP_HEADERS
+var (
+	otlpEndpoint       = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	otlpTracesEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+)
+
+var tracer = otel.Tracer("github.com/izyak/balances_tracker")
+
+// install the otlp tracer provider, the returned func flushes the spans
+// left at exit
+func setupTracing() func() {
+	if otlpEndpoint == "" && otlpTracesEndpoint == "" {
+		return func() {}
+	}
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		slog.Error("Setting up trace export failed", "err", err)
+		return func() {}
+	}
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(attribute.String("service.name", "balance-tracker")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		slog.Error("Detecting trace resource failed", "err", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Error("Flushing traces failed", "err", err)
+		}
+	}
+}
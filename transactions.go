@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Transaction struct {
+	Hash     string
+	From     string
+	To       string
+	Amount   string
+	Time     time.Time
+	Outgoing bool
+	URL      string
+}
+
+func (t Transaction) summary(coin string) string {
+	direction := "in"
+	if t.Outgoing {
+		direction = "out"
+	}
+	s := direction
+	if t.Amount != "" {
+		s += " " + t.Amount + " " + coin
+	}
+	if !t.Time.IsZero() {
+		s += " at " + t.Time.UTC().Format(time.RFC3339)
+	}
+	hash := t.Hash
+	if len(hash) > 14 {
+		hash = hash[:8] + "…" + hash[len(hash)-4:]
+	}
+	return s + fmt.Sprintf(" [%s](%s)", hash, t.URL)
+}
+
+// explorer link of an address, the explorer is either a url template with an
+// {address} placeholder or the base the address is appended to
+func explorerURL(explorer, address string) string {
+	if explorer == "" || address == "" {
+		return ""
+	}
+	if strings.Contains(explorer, "{address}") {
+		return strings.ReplaceAll(explorer, "{address}", address)
+	}
+	return explorer + "/" + address
+}
+
+// explorer link of a transaction, derived from the address explorer when not
+// configured, tx explorers may use a {hash} placeholder
+func txURL(networkConfig NetworkConfig, hash string) string {
+	base := networkConfig.TxExplorer
+	if strings.Contains(base, "{hash}") {
+		return strings.ReplaceAll(base, "{hash}", hash)
+	}
+	if base == "" {
+		path := "/tx"
+		if networkConfig.Type == "icon" {
+			path = "/transaction"
+		}
+		// sui explorers list accounts under /account
+		explorer := strings.TrimSuffix(networkConfig.Explorer, "/{address}")
+		base = strings.TrimSuffix(strings.TrimSuffix(explorer, "/address"), "/account") + path
+	}
+	return base + "/" + hash
+}
+
+// most recent transactions of an address, empty where the chain or config does not support it
+func recentTransactions(ctx context.Context, networkConfig NetworkConfig, address string, limit int) ([]Transaction, error) {
+	switch networkConfig.Type {
+	case "evm":
+		if networkConfig.TxAPI == "" {
+			return nil, nil
+		}
+		return getEtherscanTransactions(ctx, networkConfig, address, limit)
+	case "icon":
+		if networkConfig.TxAPI == "" {
+			return nil, nil
+		}
+		return getICONTrackerTransactions(ctx, networkConfig, address, limit)
+	case "cosmos":
+		return getCosmosTransactions(ctx, networkConfig, address, limit)
+	}
+	return nil, nil
+}
+
+func getJSON(ctx context.Context, apiURL string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func postJSON(ctx context.Context, apiURL string, body, result any) error {
+	jsonReq, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewBuffer(jsonReq))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type EtherscanTransactions struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  json.RawMessage
+}
+
+// etherscan compatible account txlist api
+func getEtherscanTransactions(ctx context.Context, networkConfig NetworkConfig, address string, limit int) ([]Transaction, error) {
+	query := url.Values{
+		"module":  {"account"},
+		"action":  {"txlist"},
+		"address": {address},
+		"sort":    {"desc"},
+		"page":    {"1"},
+		"offset":  {strconv.Itoa(limit)},
+	}
+	if networkConfig.TxAPIKeyEnv != "" {
+		query.Set("apikey", os.Getenv(networkConfig.TxAPIKeyEnv))
+	}
+	var res EtherscanTransactions
+	if err := getJSON(ctx, networkConfig.TxAPI+"?"+query.Encode(), &res); err != nil {
+		return nil, err
+	}
+	var list []struct {
+		Hash      string `json:"hash"`
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Value     string `json:"value"`
+		TimeStamp string `json:"timeStamp"`
+	}
+	if err := json.Unmarshal(res.Result, &list); err != nil {
+		// errors are reported as a string result
+		if res.Message == "No transactions found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %s", res.Message, res.Result)
+	}
+
+	var txs []Transaction
+	for _, t := range list {
+		tx := Transaction{
+			Hash:     t.Hash,
+			From:     t.From,
+			To:       t.To,
+			Outgoing: strings.EqualFold(t.From, address),
+			URL:      txURL(networkConfig, t.Hash),
+		}
+		if wei, ok := new(big.Int).SetString(t.Value, 10); ok {
+			tx.Amount = toDecimalUnit(wei, networkConfig.Decimals).String()
+		}
+		if ts, err := strconv.ParseInt(t.TimeStamp, 10, 64); err == nil {
+			tx.Time = time.Unix(ts, 0)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// icon tracker api, tx_api is the api base e.g. https://tracker.icon.community/api/v1
+func getICONTrackerTransactions(ctx context.Context, networkConfig NetworkConfig, address string, limit int) ([]Transaction, error) {
+	apiURL := fmt.Sprintf("%s/transactions/address/%s?limit=%d&skip=0", strings.TrimSuffix(networkConfig.TxAPI, "/"), address, limit)
+	var list []struct {
+		Hash           string  `json:"hash"`
+		FromAddress    string  `json:"from_address"`
+		ToAddress      string  `json:"to_address"`
+		ValueDecimal   float64 `json:"value_decimal"`
+		BlockTimestamp int64   `json:"block_timestamp"`
+	}
+	if err := getJSON(ctx, apiURL, &list); err != nil {
+		return nil, err
+	}
+
+	var txs []Transaction
+	for _, t := range list {
+		txs = append(txs, Transaction{
+			Hash:     t.Hash,
+			From:     t.FromAddress,
+			To:       t.ToAddress,
+			Amount:   strconv.FormatFloat(t.ValueDecimal, 'f', -1, 64),
+			Time:     time.UnixMicro(t.BlockTimestamp),
+			Outgoing: t.FromAddress == address,
+			URL:      txURL(networkConfig, t.Hash),
+		})
+	}
+	return txs, nil
+}
+
+type CosmosTxs struct {
+	TxResponses []struct {
+		TxHash    string    `json:"txhash"`
+		Timestamp time.Time `json:"timestamp"`
+		Tx        struct {
+			Body struct {
+				Messages []struct {
+					Type        string     `json:"@type"`
+					FromAddress string     `json:"from_address"`
+					ToAddress   string     `json:"to_address"`
+					Amount      []Balances `json:"amount"`
+				} `json:"messages"`
+			} `json:"body"`
+		} `json:"tx"`
+	} `json:"tx_responses"`
+}
+
+// transactions signed by the address from the lcd tx search
+func getCosmosTransactions(ctx context.Context, networkConfig NetworkConfig, address string, limit int) ([]Transaction, error) {
+	event := fmt.Sprintf("message.sender='%s'", address)
+	query := url.Values{
+		"order_by": {"ORDER_BY_DESC"},
+		"limit":    {strconv.Itoa(limit)},
+	}
+	// sdk 0.50 takes a query, older versions a list of events
+	var res CosmosTxs
+	query.Set("query", event)
+	err := getJSON(ctx, networkConfig.RPC+"/cosmos/tx/v1beta1/txs?"+query.Encode(), &res)
+	if err != nil {
+		query.Del("query")
+		query.Set("events", event)
+		if err := getJSON(ctx, networkConfig.RPC+"/cosmos/tx/v1beta1/txs?"+query.Encode(), &res); err != nil {
+			return nil, err
+		}
+	}
+
+	var txs []Transaction
+	for _, t := range res.TxResponses {
+		tx := Transaction{
+			Hash:     t.TxHash,
+			From:     address,
+			Time:     t.Timestamp,
+			Outgoing: true,
+			URL:      txURL(networkConfig, t.TxHash),
+		}
+		for _, msg := range t.Tx.Body.Messages {
+			if msg.Type != "/cosmos.bank.v1beta1.MsgSend" {
+				continue
+			}
+			tx.To = msg.ToAddress
+			for _, c := range msg.Amount {
+				if amount, ok := new(big.Int).SetString(c.Amount, 10); ok && strings.EqualFold(c.Denom, networkConfig.Coin) {
+					tx.Amount = toDecimalUnit(amount, networkConfig.Decimals).String()
+				}
+			}
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
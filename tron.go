@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+type TronAccount struct {
+	Balance int64 `json:"balance"`
+}
+
+// check a base58check tron address, they start with the 0x41 prefix byte
+func validTronAddress(address string) error {
+	data, err := base58Decode(address)
+	if err != nil {
+		return err
+	}
+	if len(data) != 25 || data[0] != 0x41 {
+		return errors.New("invalid tron address")
+	}
+	first := sha256.Sum256(data[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], data[21:]) {
+		return errors.New("invalid tron address checksum")
+	}
+	return nil
+}
+
+// balance in sun, accounts that were never activated have none
+func getTronBalance(ctx context.Context, rpcURL, address string) (*big.Int, error) {
+	if err := validTronAddress(address); err != nil {
+		return nil, err
+	}
+	var account TronAccount
+	req := map[string]any{"address": address, "visible": true}
+	if err := postJSON(ctx, rpcURL+"/wallet/getaccount", req, &account); err != nil {
+		return nil, err
+	}
+	return big.NewInt(account.Balance), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var (
+	twilioAccountSID = os.Getenv("TWILIO_ACCOUNT_SID")
+	twilioAuthToken  = os.Getenv("TWILIO_AUTH_TOKEN")
+	twilioFrom       = os.Getenv("TWILIO_FROM")
+	// comma separated phone numbers
+	twilioTo = os.Getenv("TWILIO_TO")
+)
+
+// text the on-call phones about critical breaches, anything else waits for chat
+func sendTwilioSMS(alert Alert) error {
+	if alert.Kind != alertBreach || alert.Severity != severityCritical {
+		return nil
+	}
+	body := fmt.Sprintf("%s %s %s below threshold: %s %s (threshold %s)", alert.Network, alert.Wallet, alert.subject(), alert.Balance, alert.Coin, alert.Threshold)
+	apiURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", twilioAccountSID)
+	var failed error
+	for _, to := range strings.Split(twilioTo, ",") {
+		form := url.Values{
+			"From": {twilioFrom},
+			"To":   {strings.TrimSpace(to)},
+			"Body": {body},
+		}
+		// a retry only texts the number that failed
+		err := retrySend(func() error {
+			req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth(twilioAccountSID, twilioAuthToken)
+
+			resp, err := sinkClient.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			}
+			return nil
+		})
+		if err != nil {
+			failed = err
+		}
+	}
+	return failed
+}
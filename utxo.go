@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+)
+
+type EsploraUTXO struct {
+	Value  int64 `json:"value"`
+	Status struct {
+		Confirmed bool `json:"confirmed"`
+	} `json:"status"`
+}
+
+// sum of the confirmed unspent outputs of an address in satoshis
+func getUTXOBalance(ctx context.Context, esplora, address string) (*big.Int, error) {
+	var utxos []EsploraUTXO
+	if err := getJSON(ctx, fmt.Sprintf("%s/address/%s/utxo", esplora, url.PathEscape(address)), &utxos); err != nil {
+		return nil, err
+	}
+	balance := new(big.Int)
+	for _, u := range utxos {
+		if u.Status.Confirmed {
+			balance.Add(balance, big.NewInt(u.Value))
+		}
+	}
+	return balance, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// wallet prints the detail view of a single wallet
+func wallet(args []string) error {
+	fs := flag.NewFlagSet("wallet", flag.ExitOnError)
+	limit := fs.Int("n", 10, "number of recent transfers to show, 0 to skip")
+	configFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: wallet [flags] <network> <wallet name or address>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("network and wallet are required")
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	networkConfig, w, err := chainCfg.findWallet(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fmt.Printf("Network:   %s (%s)\n", networkConfig.Name, networkConfig.Type)
+	fmt.Printf("Wallet:    %s\n", w.Name)
+	fmt.Printf("Address:   %s\n", cmp.Or(explorerURL(networkConfig.Explorer, w.Address), w.Address))
+	if w.Owner != "" {
+		fmt.Printf("Owner:     %s\n", w.Owner)
+	}
+	fmt.Printf("Threshold: %s %s\n", networkConfig.Threshold, networkConfig.Coin)
+	if w.Kind == walletKindContract {
+		for _, detail := range contractDetails(ctx, chainCfg.Format, networkConfig, w) {
+			fmt.Printf("Contract:  %s\n", detail)
+		}
+	}
+
+	// fetch the balance even if alerts are disabled for the wallet
+	w.Alert = true
+	networkConfig.Wallets = []Wallet{w}
+	results, err := fetchBalances(ctx, networkConfig, nil)
+	switch {
+	case err != nil:
+		fmt.Printf("Balance:   error: %v\n", err)
+	case results[0].Err != nil:
+		fmt.Printf("Balance:   error: %v\n", results[0].Err)
+	default:
+		status := "ok"
+		critical, warning := networkConfig.thresholds(w)
+		switch {
+		case critical != nil && exceedsBalanceThreshold(results[0].Balance, critical):
+			status = "below threshold"
+		case warning != nil && exceedsBalanceThreshold(results[0].Balance, warning):
+			status = "below warning threshold"
+		}
+		fmt.Printf("Balance:   %s %s (%s)\n", chainCfg.Format.format(results[0].Balance), networkConfig.Coin, status)
+	}
+
+	st, err := newStateStore(chainCfg.statePath()).load()
+	if err != nil {
+		return err
+	}
+	if ws, ok := st.Wallets[walletKey(networkConfig.Name, w.Address)]; ok {
+		if ws.Breached {
+			fmt.Printf("Alerting:  since %s\n", ws.BreachedSince.UTC().Format(time.RFC3339))
+		}
+		if ws.Acknowledged {
+			fmt.Printf("Acked by:  %s\n", ws.AcknowledgedBy)
+		}
+		if time.Now().Before(ws.SnoozedUntil) {
+			fmt.Printf("Snoozed:   until %s by %s\n", ws.SnoozedUntil.UTC().Format(time.RFC3339), ws.SnoozedBy)
+		}
+	}
+
+	if *limit <= 0 {
+		return nil
+	}
+	txs, err := recentTransactions(ctx, networkConfig, w.Address, *limit)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nRecent transfers\n%s\n", strings.Repeat("-", 16))
+	if len(txs) == 0 {
+		fmt.Println("No transfers found or not supported for this network")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Time\tDirection\tAmount\tCounterparty\tHash")
+	for _, tx := range txs {
+		direction, counterparty := "in", tx.From
+		if tx.Outgoing {
+			direction, counterparty = "out", tx.To
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", tx.Time.UTC().Format(time.RFC3339), direction, tx.Amount, counterparty, tx.URL)
+	}
+	return tw.Flush()
+}
+
+// find a wallet by network and wallet name or address
+func (c *ChainConfig) findWallet(network, wallet string) (NetworkConfig, Wallet, error) {
+	for _, networkConfig := range c.Chains {
+		if !strings.EqualFold(networkConfig.Name, network) {
+			continue
+		}
+		for _, w := range networkConfig.Wallets {
+			if strings.EqualFold(w.Name, wallet) || strings.EqualFold(w.Address, wallet) {
+				return networkConfig, w, nil
+			}
+		}
+		return networkConfig, Wallet{}, fmt.Errorf("no wallet %s in %s", wallet, network)
+	}
+	return NetworkConfig{}, Wallet{}, fmt.Errorf("no network named %s", network)
+}
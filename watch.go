@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	watchRetry = 10 * time.Second
+	// internal transfers of contract calls do not show up in the block's transactions
+	watchEveryBlock bool
+)
+
+// watch re-checks wallets as soon as new blocks touch them
+func watch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	fs.BoolVar(&watchEveryBlock, "every-block", false, "re-check all wallets of evm networks on every new block")
+	configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	chainCfg, err := loadConfig(filePath)
+	if err != nil {
+		return err
+	}
+	m := newMonitor(chainCfg)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	if m.startWatchers(ctx, &wg) == 0 {
+		return fmt.Errorf("no network has a ws endpoint to watch")
+	}
+	if chainCfg.RateLimit != nil {
+		per, _ := time.ParseDuration(chainCfg.RateLimit.Per)
+		go func() {
+			for range time.Tick(per) {
+				sendSuppressed()
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// keep watching every network with a ws endpoint until ctx is cancelled and
+// return how many are watched
+func (m *monitor) startWatchers(ctx context.Context, wg *sync.WaitGroup) int {
+	watching := 0
+	for _, networkConfig := range m.chainCfg.Chains {
+		w := m.watcher(networkConfig)
+		if w == nil {
+			continue
+		}
+		watching++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keepWatching(ctx, networkConfig.Name, w)
+		}()
+	}
+	return watching
+}
+
+const (
+	// a websocket silent for longer is taken for dead and reconnected
+	wsPongWait = time.Minute
+	// the server is pinged well within the wait
+	wsPingPeriod = wsPongWait / 2
+)
+
+// dial a watcher's websocket, it is pinged until ctx is cancelled and reads
+// fail once the server stops answering, so a half-open connection
+// reconnects instead of hanging
+func dialWatch(ctx context.Context, url string) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				// control frames may be written alongside the watcher's writes
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+	return conn, nil
+}
+
+// subscription based watcher of a network, nil if it has none
+func (m *monitor) watcher(networkConfig NetworkConfig) func(context.Context) error {
+	if networkConfig.WS == "" {
+		return nil
+	}
+	switch networkConfig.Type {
+	case "evm":
+		return func(ctx context.Context) error {
+			return m.watchEVM(ctx, networkConfig)
+		}
+	case "icon":
+		return func(ctx context.Context) error {
+			return m.watchICON(ctx, networkConfig)
+		}
+	case "cosmos":
+		return func(ctx context.Context) error {
+			return m.watchCosmos(ctx, networkConfig)
+		}
+	}
+	slog.Warn("Watching is not supported, skipping", "chain", networkConfig.Name, "type", networkConfig.Type)
+	return nil
+}
+
+// keep a watcher running, reconnecting after failures
+func keepWatching(ctx context.Context, name string, w func(context.Context) error) {
+	for {
+		slog.Info("Watching", "chain", name)
+		err := w(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Watcher stopped, reconnecting", "chain", name, "retry_in", watchRetry, "err", err)
+		select {
+		case <-time.After(watchRetry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// alert enabled wallets of a network keyed by lower case address
+func watchedWallets(networkConfig NetworkConfig) map[string]Wallet {
+	wallets := make(map[string]Wallet)
+	for _, wallet := range networkConfig.Wallets {
+		if wallet.Alert {
+			wallets[strings.ToLower(wallet.Address)] = wallet
+		}
+	}
+	return wallets
+}
+
+// wallets matching any of the given addresses, each at most once
+func affectedWallets(wallets map[string]Wallet, addresses ...string) []Wallet {
+	var affected []Wallet
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		address = strings.ToLower(address)
+		if wallet, ok := wallets[address]; ok && !seen[address] {
+			seen[address] = true
+			affected = append(affected, wallet)
+		}
+	}
+	return affected
+}
+
+func (m *monitor) recheckAffected(ctx context.Context, networkConfig NetworkConfig, affected []Wallet) {
+	if len(affected) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, networkConfig.chainTimeout())
+	defer cancel()
+	if err := m.recheck(ctx, networkConfig, affected); err != nil {
+		slog.Error("Re-checking failed", "chain", networkConfig.Name, "err", err)
+	}
+}
+
+type evmHead struct {
+	Hash   string `json:"hash"`
+	Number string `json:"number"`
+}
+
+type evmBlock struct {
+	Transactions []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"transactions"`
+}
+
+// subscribe to new heads and re-check wallets sending or receiving in them,
+// or all wallets with -every-block
+func (m *monitor) watchEVM(ctx context.Context, networkConfig NetworkConfig) error {
+	client, err := rpc.DialContext(ctx, networkConfig.WS)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	heads := make(chan *evmHead)
+	sub, err := client.EthSubscribe(ctx, heads, "newHeads")
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	wallets := watchedWallets(networkConfig)
+	for {
+		select {
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case head := <-heads:
+			if watchEveryBlock {
+				m.recheckAffected(ctx, networkConfig, networkConfig.Wallets)
+				continue
+			}
+			var block evmBlock
+			if err := client.CallContext(ctx, &block, "eth_getBlockByHash", head.Hash, true); err != nil {
+				return err
+			}
+			var addresses []string
+			for _, tx := range block.Transactions {
+				addresses = append(addresses, tx.From, tx.To)
+			}
+			m.recheckAffected(ctx, networkConfig, affectedWallets(wallets, addresses...))
+		}
+	}
+}
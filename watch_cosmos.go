@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+type tendermintSubscribe struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      int               `json:"id"`
+	Method  string            `json:"method"`
+	Params  map[string]string `json:"params"`
+}
+
+type tendermintEvent struct {
+	Error  *JSONRPCError `json:"error"`
+	Result struct {
+		Events map[string][]string `json:"events"`
+	} `json:"result"`
+}
+
+// subscribe to the transactions over the tendermint websocket and pick the
+// transfers of the wallets, the ws endpoint is the rpc websocket url e.g.
+// wss://rpc.host/websocket
+func (m *monitor) watchCosmos(ctx context.Context, networkConfig NetworkConfig) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, err := dialWatch(ctx, networkConfig.WS)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// a single subscription, nodes allow only a few per client (5 by default)
+	if err := conn.WriteJSON(tendermintSubscribe{JSONRPC: "2.0", ID: 1, Method: "subscribe", Params: map[string]string{"query": "tm.event='Tx'"}}); err != nil {
+		return err
+	}
+	wallets := watchedWallets(networkConfig)
+
+	for {
+		var ev tendermintEvent
+		// re-checks may have taken a while since the last pong
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		if err := conn.ReadJSON(&ev); err != nil {
+			return err
+		}
+		if ev.Error != nil {
+			return ev.Error
+		}
+		// subscription acknowledgements carry no events
+		addresses := append(ev.Result.Events["transfer.sender"], ev.Result.Events["transfer.recipient"]...)
+		m.recheckAffected(ctx, networkConfig, affectedWallets(wallets, addresses...))
+	}
+}
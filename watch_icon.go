@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type iconBlockRequest struct {
+	Height string `json:"height"`
+}
+
+type iconBlockNotification struct {
+	Code    *int   `json:"code"`
+	Message string `json:"message"`
+	Hash    string `json:"hash"`
+	Height  string `json:"height"`
+}
+
+type iconBlock struct {
+	Height       int64 `json:"height"`
+	Transactions []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"confirmed_transaction_list"`
+}
+
+// follow the goloop block websocket and re-check wallets in new transactions,
+// the ws endpoint is the block monitor url e.g. wss://host/api/v3/icon_dex/block
+func (m *monitor) watchICON(ctx context.Context, networkConfig NetworkConfig) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var last iconBlock
+	if err := callJSONRPC(ctx, networkConfig.RPC, "icx_getLastBlock", nil, &last); err != nil {
+		return err
+	}
+
+	conn, err := dialWatch(ctx, networkConfig.WS)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(iconBlockRequest{Height: "0x" + strconv.FormatInt(last.Height+1, 16)}); err != nil {
+		return err
+	}
+
+	wallets := watchedWallets(networkConfig)
+	for {
+		var n iconBlockNotification
+		// re-checks may have taken a while since the last pong
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		if err := conn.ReadJSON(&n); err != nil {
+			return err
+		}
+		if n.Code != nil {
+			if *n.Code != 0 {
+				return fmt.Errorf("block monitor error %d: %s", *n.Code, n.Message)
+			}
+			continue
+		}
+
+		var block iconBlock
+		if err := callJSONRPC(ctx, networkConfig.RPC, "icx_getBlockByHeight", map[string]string{"height": n.Height}, &block); err != nil {
+			return err
+		}
+		var addresses []string
+		for _, tx := range block.Transactions {
+			addresses = append(addresses, tx.From, tx.To)
+		}
+		m.recheckAffected(ctx, networkConfig, affectedWallets(wallets, addresses...))
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type WebhookPayload struct {
+	Event   string `json:"event"`
+	Chain   string `json:"chain"`
+	Wallet  string `json:"wallet"`
+	Address string `json:"address"`
+	Check   string `json:"check,omitempty"`
+	Target  string `json:"target,omitempty"`
+	// text in the configured number format, for display only
+	Balance   string `json:"balance"`
+	Threshold string `json:"threshold"`
+	// plain decimals in display units and the balance in base units, like
+	// the raw_balance of the firehose
+	BalanceDecimal   string    `json:"balance_decimal,omitempty"`
+	ThresholdDecimal string    `json:"threshold_decimal,omitempty"`
+	RawBalance       string    `json:"raw_balance,omitempty"`
+	Coin             string    `json:"coin"`
+	Severity         string    `json:"severity"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+var alertEvents = map[alertKind]string{
+	alertBreach:   "breach",
+	alertRecovery: "recovery",
+	alertBudget:   "budget",
+	alertDigest:   "digest",
+}
+
+func webhookPayload(alert Alert) WebhookPayload {
+	p := WebhookPayload{
+		Event:            alertEvents[alert.Kind],
+		Chain:            alert.Network,
+		Wallet:           alert.Wallet,
+		Address:          alert.Address,
+		Check:            alert.Check,
+		Target:           alert.Target,
+		Balance:          alert.Balance,
+		Threshold:        alert.Threshold,
+		BalanceDecimal:   floatString(alert.Amount),
+		ThresholdDecimal: floatString(alert.Limit),
+		Coin:             alert.Coin,
+		Severity:         alert.Severity,
+		Timestamp:        time.Now().UTC(),
+	}
+	if alert.RawAmount != nil {
+		p.RawBalance = alert.RawAmount.String()
+	}
+	// budget alerts compare the projected spend against the budget
+	if alert.Kind == alertBudget {
+		p.Balance, p.Threshold = alert.Projected, alert.Budget
+	}
+	return p
+}
+
+// post the alert as json to each configured webhook
+func sendWebhooks(urls []string, alert Alert) error {
+	jsonMsg, err := json.Marshal(webhookPayload(alert))
+	if err != nil {
+		return err
+	}
+	var failed error
+	for _, u := range urls {
+		// a retry only posts again to the url that failed
+		err := retrySend(func() error {
+			resp, err := sinkClient.Post(u, "application/json", bytes.NewBuffer(jsonMsg))
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				return fmt.Errorf("webhook %s: unexpected status code: %d", u, resp.StatusCode)
+			}
+			return nil
+		})
+		if err != nil {
+			failed = err
+		}
+	}
+	return failed
+}
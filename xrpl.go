@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+type XRPLRequest struct {
+	Method string `json:"method"`
+	Params []any  `json:"params"`
+}
+
+type XRPLAccountInfo struct {
+	Result struct {
+		Status       string `json:"status"`
+		Error        string `json:"error"`
+		ErrorMessage string `json:"error_message"`
+		AccountData  struct {
+			Balance    string `json:"Balance"`
+			OwnerCount int64  `json:"OwnerCount"`
+		} `json:"account_data"`
+	} `json:"result"`
+}
+
+type XRPLServerState struct {
+	Result struct {
+		State struct {
+			ValidatedLedger struct {
+				ReserveBase int64 `json:"reserve_base"`
+				ReserveInc  int64 `json:"reserve_inc"`
+			} `json:"validated_ledger"`
+		} `json:"state"`
+	} `json:"result"`
+}
+
+// spendable drops, the balance less the base reserve and the reserve of
+// every object the account owns
+func getXRPLBalance(ctx context.Context, rpcURL, account string) (*big.Int, error) {
+	var info XRPLAccountInfo
+	req := XRPLRequest{Method: "account_info", Params: []any{map[string]string{"account": account, "ledger_index": "validated"}}}
+	if err := postJSON(ctx, rpcURL, req, &info); err != nil {
+		return nil, err
+	}
+	if info.Result.Status != "success" {
+		return nil, fmt.Errorf("xrpl error %s: %s", info.Result.Error, info.Result.ErrorMessage)
+	}
+	balance, ok := new(big.Int).SetString(info.Result.AccountData.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid xrpl balance %q", info.Result.AccountData.Balance)
+	}
+
+	var state XRPLServerState
+	if err := postJSON(ctx, rpcURL, XRPLRequest{Method: "server_state", Params: []any{map[string]any{}}}, &state); err != nil {
+		return nil, err
+	}
+	ledger := state.Result.State.ValidatedLedger
+	balance.Sub(balance, big.NewInt(ledger.ReserveBase+ledger.ReserveInc*info.Result.AccountData.OwnerCount))
+	if balance.Sign() < 0 {
+		balance.SetInt64(0)
+	}
+	return balance, nil
+}